@@ -0,0 +1,496 @@
+// Package expr parses and evaluates band math expressions, such as (b4-b3)/(b4+b3), computing a value per pixel
+// from the values of the bands of a raster.
+//
+// An expression is made of:
+//   - numbers, e.g. 2, 0.5 or 1e-3, and the constants pi and e
+//   - bands, b1 being the first band of the raster
+//   - the arithmetic operators +, -, *, / and % and ^ for powers
+//   - the comparisons <, <=, >, >=, == and != and the logical operators &&, || and !, which are 1 when true and 0 when false.
+//     Any value other than 0 and NaN is true
+//   - the functions listed by FunctionNames, including if(condition, then, else) and the index presets such as ndvi(nir, red)
+//
+// Pixels where any band read by the expression is NaN (NoData) are NaN.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed band math expression, safe for concurrent use.
+type Expression struct {
+	source string
+	//the bands read by the expression, starting from 1, in ascending order
+	bands []int
+	root  node
+}
+
+// node evaluates a part of the expression, values holding the value of each band of the expression in the order of Bands.
+type node func(values []float64) float64
+
+// Parse parses the expression.
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+
+	p := &parser{tokens: tokens, bands: make(map[int]*int)}
+	root, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+
+	bands := make([]int, 0, len(p.bands))
+	for band := range p.bands {
+		bands = append(bands, band)
+	}
+	sort.Ints(bands)
+
+	//the nodes of the bands read their value at the position of the band in Bands
+	for i, band := range bands {
+		*p.bands[band] = i
+	}
+
+	return &Expression{source: source, bands: bands, root: root}, nil
+}
+
+// Bands returns the bands read by the expression, starting from 1, in ascending order.
+func (e *Expression) Bands() []int {
+	return e.bands
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Evaluate computes the expression for a pixel, values holding the value of each band of Bands, in the same order.
+// It returns NaN when any of the values is NaN.
+func (e *Expression) Evaluate(values []float64) float64 {
+	for _, v := range values {
+		if math.IsNaN(v) {
+			return math.NaN()
+		}
+	}
+
+	return e.root(values)
+}
+
+// EvaluateBands computes the expression for every pixel of bands, which hold the data of each band of Bands, in the same order.
+// It returns nil for expressions that do not read any band.
+func (e *Expression) EvaluateBands(bands [][]float64) []float64 {
+	if len(bands) == 0 {
+		return nil
+	}
+
+	out := make([]float64, len(bands[0]))
+	values := make([]float64, len(bands))
+	for i := range out {
+		for b, band := range bands {
+			values[b] = band[i]
+		}
+		out[i] = e.Evaluate(values)
+	}
+
+	return out
+}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenIdentifier
+	tokenOperator
+	tokenEnd
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	number   float64
+	position int
+}
+
+// operators are matched longest first
+var operators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "%", "^", "(", ")", ",", "<", ">", "!"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		r := rune(source[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			//exponent, e.g. 1e-3
+			if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+				j := i + 1
+				if j < len(source) && (source[j] == '+' || source[j] == '-') {
+					j++
+				}
+				if j < len(source) && unicode.IsDigit(rune(source[j])) {
+					for i = j; i < len(source) && unicode.IsDigit(rune(source[i])); i++ {
+					}
+				}
+			}
+
+			number, err := strconv.ParseFloat(source[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", source[start:i], start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], number: number, position: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: strings.ToLower(source[start:i]), position: start})
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(source[i:], operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, position: i})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", source[i], i)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, position: len(source)}), nil
+}
+
+// maxDepth is the deepest nesting of parentheses, function calls, unary operators and powers of an expression
+const maxDepth = 100
+
+// parser is a recursive descent parser, one method per precedence level from the lowest to the highest:
+// ||, &&, comparisons, + and -, *, / and %, unary operators, ^ and finally numbers, bands, functions and parentheses.
+type parser struct {
+	tokens []token
+	next   int
+	//nesting of the parentheses, function calls, unary operators and powers being parsed
+	depth int
+	//index of every band in the values given to the nodes, by band, set once every band is known
+	bands map[int]*int
+}
+
+func (p *parser) parse() (node, error) {
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, unexpected(t)
+	}
+	return root, nil
+}
+
+// nest enters a nested part of the expression, whose depth is limited so that the recursion of the parser is bounded.
+// The returned function leaves it.
+func (p *parser) nest() (func(), error) {
+	if p.depth >= maxDepth {
+		return nil, fmt.Errorf("expression nested deeper than %d levels at position %d", maxDepth, p.peek().position)
+	}
+
+	p.depth++
+	return func() { p.depth-- }, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token when it is one of the operators.
+func (p *parser) accept(operators ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+
+	for _, operator := range operators {
+		if t.text == operator {
+			p.next++
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(operator string) error {
+	if _, ok := p.accept(operator); !ok {
+		return fmt.Errorf("expected %q, %w", operator, unexpected(p.peek()))
+	}
+	return nil
+}
+
+func unexpected(t token) error {
+	if t.kind == tokenEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.text, t.position)
+}
+
+// binary parses a left-associative sequence of operands separated by the operators.
+func (p *parser) binary(operand func() (node, error), operators map[string]func(a, b float64) float64) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(operators))
+	for operator := range operators {
+		names = append(names, operator)
+	}
+
+	for {
+		operator, ok := p.accept(names...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		l, r, apply := left, right, operators[operator]
+		left = func(values []float64) float64 {
+			return apply(l(values), r(values))
+		}
+	}
+}
+
+func (p *parser) or() (node, error) {
+	return p.binary(p.and, map[string]func(a, b float64) float64{
+		"||": func(a, b float64) float64 { return boolean(truthy(a) || truthy(b)) },
+	})
+}
+
+func (p *parser) and() (node, error) {
+	return p.binary(p.comparison, map[string]func(a, b float64) float64{
+		"&&": func(a, b float64) float64 { return boolean(truthy(a) && truthy(b)) },
+	})
+}
+
+func (p *parser) comparison() (node, error) {
+	return p.binary(p.additive, map[string]func(a, b float64) float64{
+		"<":  func(a, b float64) float64 { return boolean(a < b) },
+		"<=": func(a, b float64) float64 { return boolean(a <= b) },
+		">":  func(a, b float64) float64 { return boolean(a > b) },
+		">=": func(a, b float64) float64 { return boolean(a >= b) },
+		"==": func(a, b float64) float64 { return boolean(a == b) },
+		"!=": func(a, b float64) float64 { return boolean(a != b) },
+	})
+}
+
+func (p *parser) additive() (node, error) {
+	return p.binary(p.multiplicative, map[string]func(a, b float64) float64{
+		"+": func(a, b float64) float64 { return a + b },
+		"-": func(a, b float64) float64 { return a - b },
+	})
+}
+
+func (p *parser) multiplicative() (node, error) {
+	return p.binary(p.unary, map[string]func(a, b float64) float64{
+		"*": func(a, b float64) float64 { return a * b },
+		"/": func(a, b float64) float64 { return a / b },
+		"%": math.Mod,
+	})
+}
+
+func (p *parser) unary() (node, error) {
+	operator, ok := p.accept("-", "+", "!")
+	if !ok {
+		return p.power()
+	}
+
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator {
+	case "-":
+		return func(values []float64) float64 { return -operand(values) }, nil
+	case "!":
+		return func(values []float64) float64 { return boolean(!truthy(operand(values))) }, nil
+	}
+	return operand, nil
+}
+
+// power is right-associative and binds tighter than the unary operators on its left, so -2^2 is -4 and 2^-1 is 0.5.
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.accept("^"); !ok {
+		return base, nil
+	}
+
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(values []float64) float64 { return math.Pow(base(values), exponent(values)) }, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		p.next++
+		return constant(t.number), nil
+	case tokenIdentifier:
+		p.next++
+		if _, ok := p.accept("("); !ok {
+			return p.identifier(t)
+		}
+
+		leave, err := p.nest()
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		return p.call(t)
+	case tokenOperator:
+		if t.text == "(" {
+			p.next++
+			leave, err := p.nest()
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+
+			inner, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		}
+	}
+
+	return nil, unexpected(t)
+}
+
+// identifier parses a band or a constant.
+func (p *parser) identifier(t token) (node, error) {
+	switch t.text {
+	case "pi":
+		return constant(math.Pi), nil
+	case "e":
+		return constant(math.E), nil
+	}
+
+	if strings.HasPrefix(t.text, "b") {
+		band, err := strconv.Atoi(t.text[1:])
+		if err == nil && band >= 1 && strconv.Itoa(band) == t.text[1:] {
+			i, ok := p.bands[band]
+			if !ok {
+				i = new(int)
+				p.bands[band] = i
+			}
+			return func(values []float64) float64 { return values[*i] }, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown band or constant %q at position %d", t.text, t.position)
+}
+
+// call parses the arguments of the function named by t, after the opening parenthesis.
+func (p *parser) call(t token) (node, error) {
+	f, ok := functions[t.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", t.text, t.position)
+	}
+
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) < f.MinArgs || (f.MaxArgs >= 0 && len(args) > f.MaxArgs) {
+		return nil, fmt.Errorf("function %s at position %d takes %s, got %d", t.text, t.position, f.arity(), len(args))
+	}
+
+	return f.node(args), nil
+}
+
+// node returns the node calling the function with the arguments, whose number the function takes.
+func (f Function) node(args []node) node {
+	switch {
+	case f.fold != nil:
+		first, rest := args[0], args[1:]
+		return func(values []float64) float64 {
+			result := first(values)
+			for _, arg := range rest {
+				result = f.fold(result, arg(values))
+			}
+			return result
+		}
+	case len(args) == 1:
+		x := args[0]
+		return func(values []float64) float64 { return f.unary(x(values)) }
+	case len(args) == 2:
+		x, y := args[0], args[1]
+		return func(values []float64) float64 { return f.binary(x(values), y(values)) }
+	}
+
+	x, y, z := args[0], args[1], args[2]
+	return func(values []float64) float64 { return f.ternary(x(values), y(values), z(values)) }
+}
+
+func constant(v float64) node {
+	return func([]float64) float64 { return v }
+}
+
+func truthy(v float64) bool {
+	return v != 0 && !math.IsNaN(v)
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
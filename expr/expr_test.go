@@ -0,0 +1,155 @@
+package expr
+
+import (
+	"gotest.tools/v3/assert"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func evaluate(t *testing.T, source string, values ...float64) float64 {
+	t.Helper()
+	e, err := Parse(source)
+	assert.NilError(t, err)
+	return e.Evaluate(values)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("BANDS", func(t *testing.T) {
+		e, err := Parse("(B4 - b3) / (b4 + b3) * b10")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, e.Bands(), []int{3, 4, 10})
+		assert.Equal(t, e.String(), "(B4 - b3) / (b4 + b3) * b10")
+
+		//the values follow the order of Bands, not the order of appearance
+		assert.Equal(t, e.Evaluate([]float64{1, 3, 2}), 1.0)
+	})
+
+	t.Run("PRECEDENCE", func(t *testing.T) {
+		for source, expected := range map[string]float64{
+			"1 + 2 * 3":        7,
+			"(1 + 2) * 3":      9,
+			"10 - 4 - 3":       3,
+			"12 / 3 / 2":       2,
+			"2 ^ 3 ^ 2":        512,
+			"-2 ^ 2":           -4,
+			"2 ^ -1":           0.5,
+			"7 % 4":            3,
+			"1 + 1 == 2":       1,
+			"1 < 2 && 2 < 1":   0,
+			"1 < 2 || 2 < 1":   1,
+			"!0 + !5":          1,
+			"1e-3 * 1000":      1,
+			"round(pi * 100)":  314,
+			"log(e)":           1,
+			"-(-3)":            3,
+			"1 + +2":           3,
+			"0 || 0 && 1 == 1": 0,
+		} {
+			assert.Equal(t, evaluate(t, source), expected, source)
+		}
+	})
+
+	t.Run("FUNCTIONS", func(t *testing.T) {
+		assert.Equal(t, evaluate(t, "if(b1 > 0, sqrt(b1), abs(b1))", 16), 4.0)
+		assert.Equal(t, evaluate(t, "if(b1 > 0, sqrt(b1), abs(b1))", -3), 3.0)
+		assert.Equal(t, evaluate(t, "min(b1, 2, -1) + max(b1)", 5), 4.0)
+		assert.Equal(t, evaluate(t, "clamp(b1, 0, 1)", 5), 1.0)
+		assert.Equal(t, evaluate(t, "pow(2, 10)"), 1024.0)
+	})
+
+	t.Run("PRESETS", func(t *testing.T) {
+		assert.Equal(t, evaluate(t, "ndvi(b4, b3)", 0.25, 0.75), 0.5)
+		assert.Equal(t, evaluate(t, "ndwi(b2, b4)", 0.25, 0.75), -0.5)
+		assert.Equal(t, evaluate(t, "savi(b1, b2, 0)", 0.2, 0.6), evaluate(t, "ndvi(b1, b2)", 0.2, 0.6))
+		assert.Assert(t, math.Abs(evaluate(t, "evi(b1, b2, b3)", 0.5, 0.1, 0.05)-2.5*0.4/(0.5+0.6-0.375+1)) < 1e-12)
+	})
+
+	t.Run("NODATA", func(t *testing.T) {
+		//even when the band with NoData is not used by the branch taken
+		assert.Assert(t, math.IsNaN(evaluate(t, "if(b1 > 0, b1, b2)", 1, math.NaN())))
+		assert.Assert(t, math.IsNaN(evaluate(t, "ndvi(b1, b2)", 0, 0)))
+
+		e, err := Parse("b1 * 2 + b2")
+		assert.NilError(t, err)
+		out := e.EvaluateBands([][]float64{{1, math.NaN(), 3}, {1, 1, math.NaN()}})
+		assert.Equal(t, out[0], 3.0)
+		assert.Assert(t, math.IsNaN(out[1]) && math.IsNaN(out[2]))
+	})
+
+	t.Run("INVALID", func(t *testing.T) {
+		for source, message := range map[string]string{
+			"":              "unexpected end of expression",
+			"b1 +":          "unexpected end of expression",
+			"(b1 + b2":      `expected ")"`,
+			"b1 b2":         `unexpected "b2" at position 3`,
+			"b0 + 1":        `unknown band or constant "b0"`,
+			"b01":           `unknown band or constant "b01"`,
+			"nir - red":     `unknown band or constant "nir"`,
+			"foo(b1)":       `unknown function "foo"`,
+			"ndvi(b1)":      "function ndvi at position 0 takes 2 arguments, got 1",
+			"savi(b1)":      "takes 2 to 3 arguments",
+			"max()":         "takes at least 1 arguments",
+			"b1 $ 2":        `unexpected character '$' at position 3`,
+			"1.2.3":         `invalid number "1.2.3"`,
+			"b1 = 2":        `unexpected character '=' at position 3`,
+			"if(b1, b2 b3)": `expected ")"`,
+		} {
+			_, err := Parse(source)
+			assert.ErrorContains(t, err, message, source)
+		}
+	})
+
+	t.Run("DEPTH", func(t *testing.T) {
+		_, err := Parse(strings.Repeat("(", maxDepth) + "b1" + strings.Repeat(")", maxDepth))
+		assert.NilError(t, err)
+
+		for _, source := range []string{
+			strings.Repeat("(", 100000) + "b1" + strings.Repeat(")", 100000),
+			strings.Repeat("-", 100000) + "b1",
+			strings.Repeat("abs(", 100000) + "b1" + strings.Repeat(")", 100000),
+			"2" + strings.Repeat("^2", 100000),
+		} {
+			_, err := Parse(source)
+			assert.ErrorContains(t, err, "nested deeper than 100 levels")
+		}
+	})
+}
+
+func TestLookupFunction(t *testing.T) {
+	f, ok := LookupFunction("ndvi")
+	assert.Assert(t, ok)
+	assert.Equal(t, f.MinArgs, 2)
+	assert.Assert(t, strings.HasPrefix(f.Description, "ndvi(nir, red)"))
+
+	_, ok = LookupFunction("Functions")
+	assert.Assert(t, !ok)
+
+	names := FunctionNames()
+	assert.Equal(t, len(names), len(functions))
+	assert.Assert(t, sort.StringsAreSorted(names))
+}
+
+func TestEvaluateAllocations(t *testing.T) {
+	e, err := Parse("if(b1 + b2 > 0, ndvi(b2, b1), min(b1, b2, 0, savi(b1, b2)))")
+	assert.NilError(t, err)
+
+	values := []float64{0.2, 0.6}
+	assert.Equal(t, testing.AllocsPerRun(100, func() { e.Evaluate(values) }), 0.0)
+}
+
+func BenchmarkEvaluateBands(b *testing.B) {
+	e, err := Parse("if(b1 + b2 > 0, ndvi(b2, b1), 0)")
+	assert.NilError(b, err)
+
+	bands := [][]float64{make([]float64, 256*256), make([]float64, 256*256)}
+	for i := range bands[0] {
+		bands[0][i], bands[1][i] = float64(i%256), float64(i/256)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.EvaluateBands(bands)
+	}
+}
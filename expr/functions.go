@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Function is a function that can be called in an expression
+type Function struct {
+	MinArgs int
+	MaxArgs int // -1 for any number of arguments
+	// Description documents the arguments and the result, e.g. for the help of a UI
+	Description string
+
+	//the implementation for each number of arguments the function takes, so that calls do not allocate their arguments
+	unary   func(x float64) float64
+	binary  func(x, y float64) float64
+	ternary func(x, y, z float64) float64
+	// fold combines any number of arguments two at a time, from the first one, instead
+	fold func(a, b float64) float64
+}
+
+func (f Function) arity() string {
+	switch {
+	case f.MinArgs == f.MaxArgs:
+		return fmt.Sprintf("%d arguments", f.MinArgs)
+	case f.MaxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.MinArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.MinArgs, f.MaxArgs)
+}
+
+// functions are the functions that can be called in an expression, by name.
+// They include presets computing common spectral indices from the bands given as arguments, e.g. ndvi(b5, b4) for Landsat 8.
+var functions = map[string]Function{
+	"abs":   unary("abs", "absolute value", math.Abs),
+	"sqrt":  unary("sqrt", "square root", math.Sqrt),
+	"exp":   unary("exp", "e raised to the power of x", math.Exp),
+	"log":   unary("log", "natural logarithm", math.Log),
+	"log10": unary("log10", "base 10 logarithm", math.Log10),
+	"sin":   unary("sin", "sine of x, in radians", math.Sin),
+	"cos":   unary("cos", "cosine of x, in radians", math.Cos),
+	"tan":   unary("tan", "tangent of x, in radians", math.Tan),
+	"asin":  unary("asin", "arcsine, in radians", math.Asin),
+	"acos":  unary("acos", "arccosine, in radians", math.Acos),
+	"atan":  unary("atan", "arctangent, in radians", math.Atan),
+	"floor": unary("floor", "greatest integer below x", math.Floor),
+	"ceil":  unary("ceil", "least integer above x", math.Ceil),
+	"round": unary("round", "nearest integer, half away from zero", math.Round),
+	"atan2": {MinArgs: 2, MaxArgs: 2, Description: "atan2(y, x): arctangent of y/x, in radians, using the signs of both to find the quadrant",
+		binary: math.Atan2},
+	"pow": {MinArgs: 2, MaxArgs: 2, Description: "pow(x, y): x raised to the power of y", binary: math.Pow},
+	"min": {MinArgs: 1, MaxArgs: -1, Description: "min(x, ...): smallest argument", fold: math.Min},
+	"max": {MinArgs: 1, MaxArgs: -1, Description: "max(x, ...): greatest argument", fold: math.Max},
+	"clamp": {MinArgs: 3, MaxArgs: 3, Description: "clamp(x, low, high): x limited to [low, high]",
+		ternary: func(x, low, high float64) float64 { return math.Max(low, math.Min(high, x)) }},
+	"if": {MinArgs: 3, MaxArgs: 3, Description: "if(condition, then, else): then when condition is neither 0 nor NaN, else otherwise",
+		ternary: func(condition, then, otherwise float64) float64 {
+			if truthy(condition) {
+				return then
+			}
+			return otherwise
+		}},
+
+	//spectral indices
+	"ndvi":  normalizedDifference("ndvi(nir, red): normalized difference vegetation index, from -1 to 1"),
+	"ndwi":  normalizedDifference("ndwi(green, nir): normalized difference water index (McFeeters), from -1 to 1"),
+	"mndwi": normalizedDifference("mndwi(green, swir1): modified normalized difference water index, from -1 to 1"),
+	"ndbi":  normalizedDifference("ndbi(swir1, nir): normalized difference built-up index, from -1 to 1"),
+	"ndmi":  normalizedDifference("ndmi(nir, swir1): normalized difference moisture index, from -1 to 1"),
+	"nbr":   normalizedDifference("nbr(nir, swir2): normalized burn ratio, from -1 to 1"),
+	"ndsi":  normalizedDifference("ndsi(green, swir1): normalized difference snow index, from -1 to 1"),
+	"savi": {MinArgs: 2, MaxArgs: 3, Description: "savi(nir, red, l = 0.5): soil adjusted vegetation index, l being the soil brightness correction",
+		binary:  func(nir, red float64) float64 { return savi(nir, red, 0.5) },
+		ternary: savi},
+	"evi": {MinArgs: 3, MaxArgs: 3, Description: "evi(nir, red, blue): enhanced vegetation index, from reflectances between 0 and 1",
+		ternary: func(nir, red, blue float64) float64 { return 2.5 * (nir - red) / (nir + 6*red - 7.5*blue + 1) }},
+}
+
+// LookupFunction returns the function that can be called in an expression under the given name.
+func LookupFunction(name string) (Function, bool) {
+	f, ok := functions[name]
+	return f, ok
+}
+
+// FunctionNames returns the names of the functions that can be called in an expression, sorted.
+func FunctionNames() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func unary(name, description string, f func(float64) float64) Function {
+	return Function{MinArgs: 1, MaxArgs: 1, Description: fmt.Sprintf("%s(x): %s", name, description), unary: f}
+}
+
+// normalizedDifference returns the function (a - b) / (a + b) of its two arguments.
+func normalizedDifference(description string) Function {
+	return Function{MinArgs: 2, MaxArgs: 2, Description: description,
+		binary: func(a, b float64) float64 { return (a - b) / (a + b) }}
+}
+
+func savi(nir, red, l float64) float64 {
+	return (1 + l) * (nir - red) / (nir + red + l)
+}
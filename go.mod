@@ -2,9 +2,9 @@ module github.com/canghel3/raster2image
 
 go 1.23.2
 
-require github.com/airbusgeo/godal v0.0.12
-
 require (
-	github.com/google/go-cmp v0.5.9 // indirect
-	gotest.tools/v3 v3.5.1 // indirect
+	github.com/airbusgeo/godal v0.0.12
+	gotest.tools/v3 v3.5.1
 )
+
+require github.com/google/go-cmp v0.5.9 // indirect
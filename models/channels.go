@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Channels maps the bands of a raster to the components of the rendered image.
+// Band indices start from 1, like in GDAL.
+// A non-zero Gray selects a single band, rendered as grayscale or through the style's color map (pseudocolor),
+// otherwise Red, Green and Blue make up a composite. An Alpha of 0 means no band is used as alpha.
+type Channels struct {
+	Gray  int
+	Red   int
+	Green int
+	Blue  int
+	Alpha int
+}
+
+// Bands returns the selected bands in the order gray or red, green, blue, followed by alpha if set.
+func (c Channels) Bands() []int {
+	var bands []int
+	if c.Gray > 0 {
+		bands = []int{c.Gray}
+	} else {
+		bands = []int{c.Red, c.Green, c.Blue}
+	}
+
+	if c.Alpha > 0 {
+		bands = append(bands, c.Alpha)
+	}
+
+	return bands
+}
+
+// ParseChannels parses a raster-channels value.
+// "auto" or an empty value returns nil, in which case the bands are picked based on how many the raster has.
+// A single band index selects that band for a grayscale or pseudocolor rendering (e.g. "2"),
+// two band indices do the same with the second band as alpha (e.g. "1 2" for gray+alpha),
+// while three or four band indices make up a composite (e.g. "4 3 2" for a false-color composite or "1 2 3 4").
+// Band indices are separated by spaces or commas.
+func ParseChannels(value string) (*Channels, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "auto" {
+		return nil, nil
+	}
+
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+
+	bands := make([]int, len(fields))
+	for i, field := range fields {
+		band, err := strconv.Atoi(field)
+		if err != nil || band < 1 {
+			return nil, fmt.Errorf("invalid raster-channels band %q", field)
+		}
+		bands[i] = band
+	}
+
+	switch len(bands) {
+	case 1:
+		return &Channels{Gray: bands[0]}, nil
+	case 2:
+		return &Channels{Gray: bands[0], Alpha: bands[1]}, nil
+	case 3:
+		return &Channels{Red: bands[0], Green: bands[1], Blue: bands[2]}, nil
+	case 4:
+		return &Channels{Red: bands[0], Green: bands[1], Blue: bands[2], Alpha: bands[3]}, nil
+	}
+
+	return nil, fmt.Errorf("invalid raster-channels %q: expected auto or 1 to 4 bands", value)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestParseChannels(t *testing.T) {
+	t.Run("AUTO", func(t *testing.T) {
+		channels, err := ParseChannels("auto")
+		assert.NilError(t, err)
+		assert.Check(t, channels == nil)
+
+		channels, err = ParseChannels("")
+		assert.NilError(t, err)
+		assert.Check(t, channels == nil)
+	})
+
+	t.Run("SINGLE BAND", func(t *testing.T) {
+		channels, err := ParseChannels("2")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, *channels, Channels{Gray: 2})
+		assert.DeepEqual(t, channels.Bands(), []int{2})
+	})
+
+	t.Run("GRAY ALPHA", func(t *testing.T) {
+		channels, err := ParseChannels("1 2")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, *channels, Channels{Gray: 1, Alpha: 2})
+		assert.DeepEqual(t, channels.Bands(), []int{1, 2})
+	})
+
+	t.Run("FALSE COLOR", func(t *testing.T) {
+		channels, err := ParseChannels("4 3 2")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, *channels, Channels{Red: 4, Green: 3, Blue: 2})
+		assert.DeepEqual(t, channels.Bands(), []int{4, 3, 2})
+	})
+
+	t.Run("RGBA", func(t *testing.T) {
+		channels, err := ParseChannels("1,2,3,4")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, *channels, Channels{Red: 1, Green: 2, Blue: 3, Alpha: 4})
+		assert.DeepEqual(t, channels.Bands(), []int{1, 2, 3, 4})
+	})
+
+	t.Run("INVALID", func(t *testing.T) {
+		_, err := ParseChannels("1 2 3 4 5")
+		assert.ErrorContains(t, err, "invalid raster-channels")
+
+		_, err = ParseChannels("0 1 2")
+		assert.ErrorContains(t, err, "invalid raster-channels band")
+
+		_, err = ParseChannels("r g b")
+		assert.ErrorContains(t, err, "invalid raster-channels band")
+	})
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// RasterInfo describes a loaded raster
+type RasterInfo struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// GeoTransform maps pixel coordinates to the SRS of the raster, like GDAL's
+	GeoTransform [6]float64 `json:"geotransform"`
+	SRS          SRSInfo    `json:"srs"`
+	// Extent is the bbox of the raster in its own SRS
+	Extent [4]float64 `json:"extent"`
+	// WGS84Extent is the bbox of the raster in EPSG:4326, as longitudes and latitudes
+	WGS84Extent [4]float64 `json:"wgs84_extent"`
+	// Overviews are the decimation factors of the overviews of the raster, e.g. 2, 4, 8
+	Overviews []int      `json:"overviews"`
+	Bands     []BandInfo `json:"bands"`
+}
+
+// SRSInfo describes the spatial reference system of a raster
+type SRSInfo struct {
+	WKT string `json:"wkt"`
+	// EPSG is 0 when the SRS has no EPSG code
+	EPSG int `json:"epsg,omitempty"`
+}
+
+// BandInfo describes a band of a raster
+type BandInfo struct {
+	// Index starts from 1, like raster-channels
+	Index               int    `json:"index"`
+	DataType            string `json:"data_type"`
+	ColorInterpretation string `json:"color_interpretation"`
+	// NoData is nil when the band has no NoData value
+	NoData *JSONFloat `json:"nodata,omitempty"`
+	// Min and Max are the ones used to stretch the band, computed without NoData, NaN and infinite values
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	// Categories name the values of classified bands, from the raster attribute table or the category names of the raster.
+	// They are empty when the raster does not name its values.
+	Categories []Category `json:"categories,omitempty"`
+}
+
+// Category is a named value of a classified band, e.g. a land cover class, to build a legend from
+type Category struct {
+	Value float64 `json:"value"`
+	Name  string  `json:"name"`
+	// Color is the hex color of the value in the color table or the attribute table of the band, empty if it has none
+	Color string `json:"color,omitempty"`
+}
+
+// JSONFloat is a float64 which marshals NaN and infinite values as the strings "NaN", "Infinity" and "-Infinity",
+// like gdalinfo -json does, since JSON numbers cannot hold them
+type JSONFloat float64
+
+func (f JSONFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return json.Marshal("NaN")
+	case math.IsInf(v, 1):
+		return json.Marshal("Infinity")
+	case math.IsInf(v, -1):
+		return json.Marshal("-Infinity")
+	}
+
+	return json.Marshal(v)
+}
+
+func (f *JSONFloat) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		switch s {
+		case "NaN":
+			*f = JSONFloat(math.NaN())
+			return nil
+		case "Infinity":
+			*f = JSONFloat(math.Inf(1))
+			return nil
+		case "-Infinity":
+			*f = JSONFloat(math.Inf(-1))
+			return nil
+		}
+	}
+
+	var v float64
+	err := json.Unmarshal(data, &v)
+	*f = JSONFloat(v)
+	return err
+}
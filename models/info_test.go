@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestJSONFloat(t *testing.T) {
+	values := []JSONFloat{-9999, 0.5, JSONFloat(math.Inf(1)), JSONFloat(math.Inf(-1))}
+	data, err := json.Marshal(values)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), `[-9999,0.5,"Infinity","-Infinity"]`)
+
+	var parsed []JSONFloat
+	assert.NilError(t, json.Unmarshal(data, &parsed))
+	assert.DeepEqual(t, parsed, values)
+
+	t.Run("NAN", func(t *testing.T) {
+		noData := JSONFloat(math.NaN())
+		data, err := json.Marshal(BandInfo{Index: 1, NoData: &noData})
+		assert.NilError(t, err)
+		assert.Equal(t, string(data), `{"index":1,"data_type":"","color_interpretation":"","nodata":"NaN","min":0,"max":0}`)
+
+		var parsed BandInfo
+		assert.NilError(t, json.Unmarshal(data, &parsed))
+		assert.Assert(t, math.IsNaN(float64(*parsed.NoData)))
+	})
+}
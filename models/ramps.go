@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ColorStop is a color of a named ramp at a position from 0 (the start of the ramp) to 1 (its end)
+type ColorStop struct {
+	Position float64
+	Color    string // Hex color code
+}
+
+// evenly spreads the colors from 0 to 1
+func evenly(colors ...string) []ColorStop {
+	stops := make([]ColorStop, len(colors))
+	for i, color := range colors {
+		stops[i] = ColorStop{Position: float64(i) / float64(len(colors)-1), Color: color}
+	}
+	return stops
+}
+
+// ColorRamps are the named color ramps of raster-color-ramp, by lowercase name.
+// viridis, magma, inferno, plasma and cividis are the perceptually uniform ramps of matplotlib, terrain is matplotlib's as well.
+// The others are ColorBrewer ramps: rdbu, rdylgn, spectral, brbg and puor diverge from a light middle,
+// greys, blues, greens and ylorrd are sequential.
+var ColorRamps = map[string][]ColorStop{
+	"viridis": evenly("#440154", "#472D7B", "#3B528B", "#2C728E", "#21918C", "#28AE80", "#5EC962", "#ADDC30", "#FDE725"),
+	"magma":   evenly("#000004", "#1C1044", "#4F127B", "#812581", "#B5367A", "#E55064", "#FB8761", "#FEC287", "#FCFDBF"),
+	"inferno": evenly("#000004", "#1F0C48", "#550F6D", "#88226A", "#BA3655", "#E35933", "#F98C0A", "#F9C932", "#FCFFA4"),
+	"plasma":  evenly("#0D0887", "#4C02A1", "#7E03A8", "#A92395", "#CC4778", "#E56B5D", "#F89441", "#FDC328", "#F0F921"),
+	"cividis": evenly("#00224E", "#123570", "#3B496C", "#575D6D", "#707173", "#8A8779", "#A69D75", "#C4B56C", "#FEE838"),
+	"terrain": {
+		{Position: 0, Color: "#333399"},
+		{Position: 0.15, Color: "#0099FF"},
+		{Position: 0.25, Color: "#00CC66"},
+		{Position: 0.5, Color: "#FFFF99"},
+		{Position: 0.75, Color: "#805C54"},
+		{Position: 1, Color: "#FFFFFF"},
+	},
+	"rdbu":     evenly("#67001F", "#B2182B", "#D6604D", "#F4A582", "#FDDBC7", "#F7F7F7", "#D1E5F0", "#92C5DE", "#4393C3", "#2166AC", "#053061"),
+	"rdylgn":   evenly("#A50026", "#D73027", "#F46D43", "#FDAE61", "#FEE08B", "#FFFFBF", "#D9EF8B", "#A6D96A", "#66BD63", "#1A9850", "#006837"),
+	"spectral": evenly("#9E0142", "#D53E4F", "#F46D43", "#FDAE61", "#FEE08B", "#FFFFBF", "#E6F598", "#ABDDA4", "#66C2A5", "#3288BD", "#5E4FA2"),
+	"brbg":     evenly("#543005", "#8C510A", "#BF812D", "#DFC27D", "#F6E8C3", "#F5F5F5", "#C7EAE5", "#80CDC1", "#35978F", "#01665E", "#003C30"),
+	"puor":     evenly("#7F3B08", "#B35806", "#E08214", "#FDB863", "#FEE0B6", "#F7F7F7", "#D8DAEB", "#B2ABD2", "#8073AC", "#542788", "#2D004B"),
+	"greys":    evenly("#FFFFFF", "#F0F0F0", "#D9D9D9", "#BDBDBD", "#969696", "#737373", "#525252", "#252525", "#000000"),
+	"blues":    evenly("#F7FBFF", "#DEEBF7", "#C6DBEF", "#9ECAE1", "#6BAED6", "#4292C6", "#2171B5", "#08519C", "#08306B"),
+	"greens":   evenly("#F7FCF5", "#E5F5E0", "#C7E9C0", "#A1D99B", "#74C476", "#41AB5D", "#238B45", "#006D2C", "#00441B"),
+	"ylorrd":   evenly("#FFFFCC", "#FFEDA0", "#FED976", "#FEB24C", "#FD8D3C", "#FC4E2A", "#E31A1C", "#BD0026", "#800026"),
+}
+
+// ColorRamp is a named color ramp spread over a range of values, which replaces the color-map-entry list of a style
+type ColorRamp struct {
+	Name string // Key of ColorRamps, no ramp if empty
+	// Min and Max are the values the ramp starts and ends at. When nil, the min and max of the rendered band are used.
+	Min *float64
+	Max *float64
+	// Reverse starts the ramp at Max and ends it at Min
+	Reverse bool
+}
+
+// ParseColorRamp parses a raster-color-ramp value: <name> [min max] [reverse], e.g. "viridis", "RdBu -1 1 reverse".
+// Names are case-insensitive.
+func ParseColorRamp(value string) (ColorRamp, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return ColorRamp{}, fmt.Errorf("invalid raster-color-ramp %q: expected <name> [min max] [reverse]", value)
+	}
+
+	ramp := ColorRamp{Name: strings.ToLower(fields[0])}
+	if _, ok := ColorRamps[ramp.Name]; !ok {
+		names := make([]string, 0, len(ColorRamps))
+		for name := range ColorRamps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return ColorRamp{}, fmt.Errorf("unknown raster-color-ramp %q: expected one of %s", fields[0], strings.Join(names, ", "))
+	}
+
+	params := fields[1:]
+	if len(params) > 0 && params[len(params)-1] == "reverse" {
+		ramp.Reverse = true
+		params = params[:len(params)-1]
+	}
+
+	switch len(params) {
+	case 0:
+		return ramp, nil
+	case 2:
+		min, errMin := strconv.ParseFloat(params[0], 64)
+		max, errMax := strconv.ParseFloat(params[1], 64)
+		if errMin == nil && errMax == nil && min < max {
+			ramp.Min, ramp.Max = &min, &max
+			return ramp, nil
+		}
+	}
+
+	return ColorRamp{}, fmt.Errorf("invalid raster-color-ramp %q: expected <name> [min max] [reverse], with min below max", value)
+}
+
+// ColorMap returns the color map entries of the ramp spread from min to max, or from its own Min and Max when they are set.
+func (cr ColorRamp) ColorMap(min, max float64) ([]ColorMapEntry, error) {
+	stops, ok := ColorRamps[strings.ToLower(cr.Name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown color ramp %q", cr.Name)
+	}
+
+	if cr.Min != nil && cr.Max != nil {
+		min, max = *cr.Min, *cr.Max
+	}
+
+	entries := make([]ColorMapEntry, len(stops))
+	for i, stop := range stops {
+		position := stop.Position
+		if cr.Reverse {
+			//the colors are reversed, the entries stay sorted by quantity
+			stop = stops[len(stops)-1-i]
+			position = 1 - stop.Position
+		}
+
+		entries[i] = ColorMapEntry{
+			Color:    stop.Color,
+			Quantity: min + position*(max-min),
+			Opacity:  1,
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestParseColorRamp(t *testing.T) {
+	ramp, err := ParseColorRamp("viridis")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ramp, ColorRamp{Name: "viridis"})
+
+	ramp, err = ParseColorRamp("RdBu -1 1 reverse")
+	assert.NilError(t, err)
+	assert.Equal(t, ramp.Name, "rdbu")
+	assert.Equal(t, *ramp.Min, -1.0)
+	assert.Equal(t, *ramp.Max, 1.0)
+	assert.Assert(t, ramp.Reverse)
+
+	ramp, err = ParseColorRamp("magma reverse")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ramp, ColorRamp{Name: "magma", Reverse: true})
+
+	_, err = ParseColorRamp("rainbow")
+	assert.ErrorContains(t, err, `unknown raster-color-ramp "rainbow": expected one of blues, brbg`)
+
+	for _, value := range []string{"", "viridis 1", "viridis 1 0", "viridis low high", "viridis 0 1 2"} {
+		_, err = ParseColorRamp(value)
+		assert.ErrorContains(t, err, "invalid raster-color-ramp", value)
+	}
+}
+
+func TestColorRampColorMap(t *testing.T) {
+	for name, stops := range ColorRamps {
+		assert.Equal(t, stops[0].Position, 0.0, name)
+		assert.Equal(t, stops[len(stops)-1].Position, 1.0, name)
+	}
+
+	entries, err := ColorRamp{Name: "viridis"}.ColorMap(0, 800)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 9)
+	assert.Equal(t, entries[0], ColorMapEntry{Color: "#440154", Quantity: 0, Opacity: 1})
+	assert.Equal(t, entries[1].Quantity, 100.0)
+	assert.Equal(t, entries[8], ColorMapEntry{Color: "#FDE725", Quantity: 800, Opacity: 1})
+
+	t.Run("REVERSE", func(t *testing.T) {
+		min, max := 0.0, 1.0
+		entries, err := ColorRamp{Name: "terrain", Min: &min, Max: &max, Reverse: true}.ColorMap(-50, 50)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, entries, []ColorMapEntry{
+			{Color: "#FFFFFF", Quantity: 0, Opacity: 1},
+			{Color: "#805C54", Quantity: 0.25, Opacity: 1},
+			{Color: "#FFFF99", Quantity: 0.5, Opacity: 1},
+			{Color: "#00CC66", Quantity: 0.75, Opacity: 1},
+			{Color: "#0099FF", Quantity: 0.85, Opacity: 1},
+			{Color: "#333399", Quantity: 1, Opacity: 1},
+		})
+	})
+
+	_, err = ColorRamp{Name: "rainbow"}.ColorMap(0, 1)
+	assert.ErrorContains(t, err, "unknown color ramp")
+}
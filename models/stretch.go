@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StretchType defines the range of values mapped from black to white when a band is rendered as grayscale
+type StretchType string
+
+const (
+	// StretchMinMax maps the min of the band to black and its max to white
+	StretchMinMax StretchType = "minmax"
+	// StretchPercentile maps the Low percentile of the band to black and its High percentile to white, clipping outliers
+	StretchPercentile StretchType = "percentile"
+	// StretchStdDev maps the mean of the band minus StdDevs standard deviations to black and the mean plus StdDevs standard deviations to white
+	StretchStdDev StretchType = "stddev"
+	// StretchEqualize spreads the values so that every gray level is about as frequent, using the histogram of the band
+	StretchEqualize StretchType = "equalize"
+)
+
+// StretchCurve defines how the stretched values, from 0 to 1, are mapped to gray levels
+type StretchCurve string
+
+const (
+	// CurveLinear maps the stretched values to gray levels as they are
+	CurveLinear StretchCurve = "linear"
+	// CurveGamma raises the stretched values to the power of 1/Gamma, a Gamma above 1 brightens the dark values
+	CurveGamma StretchCurve = "gamma"
+	// CurveLog brightens the dark values logarithmically
+	CurveLog StretchCurve = "log"
+	// CurveSqrt takes the square root of the stretched values, which brightens the dark values less than CurveLog
+	CurveSqrt StretchCurve = "sqrt"
+)
+
+const (
+	// DefaultLowPercentile and DefaultHighPercentile are the percentiles of StretchPercentile, when not specified otherwise
+	DefaultLowPercentile  = 2
+	DefaultHighPercentile = 98
+	// DefaultStdDevs is the number of standard deviations of StretchStdDev, when not specified otherwise
+	DefaultStdDevs = 2
+)
+
+// Stretch defines how a band is rendered as grayscale. The zero value is a linear min-max stretch.
+type Stretch struct {
+	Type    StretchType  // Range of values mapped from black to white, minmax if empty
+	Low     float64      // Percentile mapped to black by StretchPercentile, DefaultLowPercentile if both Low and High are 0
+	High    float64      // Percentile mapped to white by StretchPercentile, DefaultHighPercentile if 0
+	StdDevs float64      // Number of standard deviations around the mean of StretchStdDev, DefaultStdDevs if 0
+	Curve   StretchCurve // How the stretched values are mapped to gray levels, linear if empty
+	Gamma   float64      // Gamma of CurveGamma
+}
+
+// WithDefaults returns the stretch with the zero percentiles of StretchPercentile set to DefaultLowPercentile and
+// DefaultHighPercentile, and the zero StdDevs of StretchStdDev set to DefaultStdDevs.
+// A Low of 0 is kept when High is set, since it stands for the min of the band.
+func (s Stretch) WithDefaults() Stretch {
+	switch s.Type {
+	case StretchPercentile:
+		if s.High == 0 {
+			if s.Low == 0 {
+				s.Low = DefaultLowPercentile
+			}
+			s.High = DefaultHighPercentile
+		}
+	case StretchStdDev:
+		if s.StdDevs == 0 {
+			s.StdDevs = DefaultStdDevs
+		}
+	}
+
+	return s
+}
+
+// ParseStretch parses a raster-stretch value: minmax, percentile [low high], stddev [count] or equalize.
+// An empty value defaults to StretchMinMax.
+func ParseStretch(value string) (Stretch, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return Stretch{Type: StretchMinMax}, nil
+	}
+
+	params, err := parseFloats(fields[1:])
+	if err != nil {
+		return Stretch{}, fmt.Errorf("invalid raster-stretch %q: %w", value, err)
+	}
+
+	switch t := StretchType(fields[0]); t {
+	case StretchMinMax, StretchEqualize:
+		if len(params) == 0 {
+			return Stretch{Type: t}, nil
+		}
+	case StretchPercentile:
+		switch len(params) {
+		case 0:
+			return Stretch{Type: t}.WithDefaults(), nil
+		case 2:
+			if params[0] >= 0 && params[0] < params[1] && params[1] <= 100 {
+				return Stretch{Type: t, Low: params[0], High: params[1]}, nil
+			}
+		}
+	case StretchStdDev:
+		switch len(params) {
+		case 0:
+			return Stretch{Type: t}.WithDefaults(), nil
+		case 1:
+			if params[0] > 0 {
+				return Stretch{Type: t, StdDevs: params[0]}, nil
+			}
+		}
+	}
+
+	return Stretch{}, fmt.Errorf("invalid raster-stretch %q: expected minmax, percentile [low high], stddev [count] or equalize", value)
+}
+
+// ParseStretchCurve parses a raster-stretch-curve value: linear, gamma <gamma>, log or sqrt.
+// It returns the curve and its gamma, which is 0 for curves other than CurveGamma. An empty value defaults to CurveLinear.
+func ParseStretchCurve(value string) (StretchCurve, float64, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return CurveLinear, 0, nil
+	}
+
+	params, err := parseFloats(fields[1:])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid raster-stretch-curve %q: %w", value, err)
+	}
+
+	switch c := StretchCurve(fields[0]); c {
+	case CurveLinear, CurveLog, CurveSqrt:
+		if len(params) == 0 {
+			return c, 0, nil
+		}
+	case CurveGamma:
+		if len(params) == 1 && params[0] > 0 {
+			return c, params[0], nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("invalid raster-stretch-curve %q: expected linear, gamma <gamma>, log or sqrt", value)
+}
+
+func parseFloats(fields []string) ([]float64, error) {
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
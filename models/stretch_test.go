@@ -0,0 +1,55 @@
+package models
+
+import (
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestParseStretch(t *testing.T) {
+	valid := map[string]Stretch{
+		"":                {Type: StretchMinMax},
+		"minmax":          {Type: StretchMinMax},
+		"percentile":      {Type: StretchPercentile, Low: 2, High: 98},
+		"percentile 1 99": {Type: StretchPercentile, Low: 1, High: 99},
+		"stddev":          {Type: StretchStdDev, StdDevs: 2},
+		"stddev 2.5":      {Type: StretchStdDev, StdDevs: 2.5},
+		"equalize":        {Type: StretchEqualize},
+	}
+	for value, expected := range valid {
+		stretch, err := ParseStretch(value)
+		assert.NilError(t, err, value)
+		assert.Equal(t, stretch, expected, value)
+	}
+
+	for _, value := range []string{"linear", "percentile 98 2", "percentile 2", "percentile 2 101", "stddev 0", "stddev x", "minmax 1"} {
+		_, err := ParseStretch(value)
+		assert.ErrorContains(t, err, "invalid raster-stretch", value)
+	}
+}
+
+func TestStretchWithDefaults(t *testing.T) {
+	assert.Equal(t, Stretch{}.WithDefaults(), Stretch{})
+	assert.Equal(t, Stretch{Type: StretchPercentile}.WithDefaults(), Stretch{Type: StretchPercentile, Low: 2, High: 98})
+	assert.Equal(t, Stretch{Type: StretchPercentile, High: 90}.WithDefaults(), Stretch{Type: StretchPercentile, High: 90})
+	assert.Equal(t, Stretch{Type: StretchPercentile, Low: 5}.WithDefaults(), Stretch{Type: StretchPercentile, Low: 5, High: 98})
+	assert.Equal(t, Stretch{Type: StretchStdDev, Curve: CurveLog}.WithDefaults(), Stretch{Type: StretchStdDev, StdDevs: 2, Curve: CurveLog})
+}
+
+func TestParseStretchCurve(t *testing.T) {
+	curve, gamma, err := ParseStretchCurve("gamma 2.2")
+	assert.NilError(t, err)
+	assert.Equal(t, curve, CurveGamma)
+	assert.Equal(t, gamma, 2.2)
+
+	for value, expected := range map[string]StretchCurve{"": CurveLinear, "linear": CurveLinear, "log": CurveLog, "sqrt": CurveSqrt} {
+		curve, gamma, err := ParseStretchCurve(value)
+		assert.NilError(t, err, value)
+		assert.Equal(t, curve, expected)
+		assert.Equal(t, gamma, 0.0)
+	}
+
+	for _, value := range []string{"gamma", "gamma 0", "gamma -1", "log 2", "cubic"} {
+		_, _, err := ParseStretchCurve(value)
+		assert.ErrorContains(t, err, "invalid raster-stretch-curve", value)
+	}
+}
@@ -1,37 +1,205 @@
 package models
 
 import (
+	"fmt"
 	"github.com/canghel3/raster2image/utils"
 	"image/color"
+	"math"
 )
 
 // ColorMapEntry represents each color map entry in the raster-color-map
 type ColorMapEntry struct {
 	Color    string  // Hex color code
 	Quantity float64 // Quantity associated with the color
-	Opacity  float64 // Opacity value
-	Label    string  // Description label
+	// Opacity is from 0 (transparent) to 1 (opaque). The zero value is transparent, so entries built in code must set it,
+	// while the CSS parser defaults it to 1 when a color-map-entry omits it
+	Opacity float64
+	Label   string // Description label
+}
+
+// NRGBA returns the non-premultiplied color of the entry, with the alpha of the hex color scaled by the entry's opacity.
+func (cme ColorMapEntry) NRGBA() color.NRGBA {
+	c := utils.HexToRGBA(cme.Color)
+	opacity := math.Max(0, math.Min(1, cme.Opacity))
+
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: uint8(math.Round(float64(c.A) * opacity))}
+}
+
+// ColorMapType defines how values are matched against the color map entries, like the type of an SLD ColorMap
+type ColorMapType string
+
+const (
+	// ColorMapRamp linearly interpolates the color and opacity between the two entries surrounding a value
+	ColorMapRamp ColorMapType = "ramp"
+	// ColorMapIntervals colors every value with the entry of the interval it falls in, see IntervalClosure
+	ColorMapIntervals ColorMapType = "intervals"
+	// ColorMapValues colors only the values that are exactly equal to the quantity of an entry, any other value is transparent
+	ColorMapValues ColorMapType = "values"
+)
+
+// ParseColorMapType parses a raster-color-map-type value. An empty value defaults to ColorMapIntervals.
+func ParseColorMapType(value string) (ColorMapType, error) {
+	switch t := ColorMapType(value); t {
+	case "":
+		return ColorMapIntervals, nil
+	case ColorMapRamp, ColorMapIntervals, ColorMapValues:
+		return t, nil
+	}
+
+	return "", fmt.Errorf("invalid raster-color-map-type %q: expected ramp, intervals or values", value)
+}
+
+// OutOfRange defines how values below the first or above the last quantity of the color map are colored.
+// It does not apply to ColorMapValues, where any value that is not in the color map is transparent.
+type OutOfRange string
+
+const (
+	// OutOfRangeClamp colors the value like the closest entry
+	OutOfRangeClamp OutOfRange = "clamp"
+	// OutOfRangeTransparent leaves the value transparent
+	OutOfRangeTransparent OutOfRange = "transparent"
+	// OutOfRangeExtend extrapolates the ramp from the two closest entries. It is the same as OutOfRangeClamp for intervals
+	OutOfRangeExtend OutOfRange = "extend"
+)
+
+// ParseOutOfRange parses a raster-color-map-below or raster-color-map-above value. An empty value defaults to OutOfRangeClamp.
+func ParseOutOfRange(value string) (OutOfRange, error) {
+	switch o := OutOfRange(value); o {
+	case "":
+		return OutOfRangeClamp, nil
+	case OutOfRangeClamp, OutOfRangeTransparent, OutOfRangeExtend:
+		return o, nil
+	}
+
+	return "", fmt.Errorf("invalid out of range behavior %q: expected clamp, transparent or extend", value)
+}
+
+// IntervalClosure defines which end of an interval belongs to it, for ColorMapIntervals.
+// The quantity of an entry is the upper bound of its interval, the previous entry's quantity is the lower one.
+// The color map range itself is closed on both ends, so the first and last quantities are never out of range.
+type IntervalClosure string
+
+const (
+	// ClosedRight intervals are (previous, quantity], the first quantity itself gets the first entry
+	ClosedRight IntervalClosure = "right"
+	// ClosedLeft intervals are [previous, quantity), the last quantity itself gets the last entry
+	ClosedLeft IntervalClosure = "left"
+)
+
+// ParseIntervalClosure parses a raster-color-map-closure value. An empty value defaults to ClosedRight.
+func ParseIntervalClosure(value string) (IntervalClosure, error) {
+	switch c := IntervalClosure(value); c {
+	case "":
+		return ClosedRight, nil
+	case ClosedRight, ClosedLeft:
+		return c, nil
+	}
+
+	return "", fmt.Errorf("invalid raster-color-map-closure %q: expected left or right", value)
 }
 
 // RasterStyle represents the entire raster style configuration
 type RasterStyle struct {
-	RasterChannels string          // Channel setting
-	ColorMap       []ColorMapEntry // List of color map entries
+	RasterChannels  string          // Channel setting
+	ColorMapType    ColorMapType    // How values are matched against the color map, intervals if empty
+	Below           OutOfRange      // How values below the first quantity are colored, clamp if empty
+	Above           OutOfRange      // How values above the last quantity are colored, clamp if empty
+	IntervalClosure IntervalClosure // Which end of an interval belongs to it, right if empty
+	Stretch         Stretch         // How the band is rendered as grayscale when there is no color map
+	Mode            RenderMode      // What is rendered from a single band, default if empty
+	Hillshade       Hillshade       // How the band is lit by ModeHillshade and ModeRelief
+	Relief          Relief          // How ModeRelief blends the hillshade with the color map
+	Slope           Slope           // How the steepness of the band is measured by ModeSlope
+	Expression      string          // Band math expression rendered instead of the bands, see package expr
+	ColorRamp       ColorRamp       // Named ramp expanded into ColorMap when the raster is loaded
+	ColorMap        []ColorMapEntry // List of color map entries, which must be sorted by quantity, as the CSS parser does
 }
 
-func (rs *RasterStyle) GetColor(value float64) color.RGBA {
-	var previous float64
+// GetColor classifies the value against the color map and returns its non-premultiplied color.
+// Values that should not be painted, including NaN, get a fully transparent color.
+func (rs *RasterStyle) GetColor(value float64) color.NRGBA {
+	if len(rs.ColorMap) == 0 || math.IsNaN(value) {
+		return color.NRGBA{}
+	}
+
+	if rs.ColorMapType == ColorMapValues {
+		for _, entry := range rs.ColorMap {
+			if entry.Quantity == value {
+				return entry.NRGBA()
+			}
+		}
+
+		return color.NRGBA{}
+	}
+
+	first, last := rs.ColorMap[0], rs.ColorMap[len(rs.ColorMap)-1]
+	if value < first.Quantity {
+		return rs.outOfRange(value, rs.Below, 0)
+	}
+	if value > last.Quantity {
+		return rs.outOfRange(value, rs.Above, len(rs.ColorMap)-1)
+	}
+
+	if rs.ColorMapType == ColorMapRamp {
+		for i := 1; i < len(rs.ColorMap); i++ {
+			if value <= rs.ColorMap[i].Quantity {
+				return rs.interpolate(i-1, i, value)
+			}
+		}
+
+		return last.NRGBA()
+	}
+
+	if rs.IntervalClosure == ClosedLeft {
+		for i := 1; i < len(rs.ColorMap); i++ {
+			if value < rs.ColorMap[i].Quantity {
+				return rs.ColorMap[i].NRGBA()
+			}
+		}
+
+		return last.NRGBA()
+	}
+
 	for i, entry := range rs.ColorMap {
-		if i == 0 {
-			previous = entry.Quantity
+		if value <= entry.Quantity {
+			return rs.ColorMap[i].NRGBA()
 		}
+	}
+
+	return last.NRGBA()
+}
 
-		if previous < value && value <= entry.Quantity {
-			return utils.HexToRGBA(entry.Color)
+// outOfRange colors a value outside the color map. closest is the index of the entry closest to the value,
+// either the first or the last one.
+func (rs *RasterStyle) outOfRange(value float64, behavior OutOfRange, closest int) color.NRGBA {
+	switch behavior {
+	case OutOfRangeTransparent:
+		return color.NRGBA{}
+	case OutOfRangeExtend:
+		if rs.ColorMapType == ColorMapRamp && len(rs.ColorMap) > 1 {
+			if closest == 0 {
+				return rs.interpolate(0, 1, value)
+			}
+			return rs.interpolate(closest-1, closest, value)
 		}
+	}
+
+	return rs.ColorMap[closest].NRGBA()
+}
 
-		previous = entry.Quantity
+// interpolate linearly interpolates the color and opacity of the entries at indices lower and upper for the value.
+// Values outside of [lower, upper] are extrapolated, clamping every component to 0-255.
+func (rs *RasterStyle) interpolate(lower, upper int, value float64) color.NRGBA {
+	l, u := rs.ColorMap[lower], rs.ColorMap[upper]
+	if u.Quantity == l.Quantity {
+		return u.NRGBA()
 	}
-	// Default to black if no range matches
-	return color.RGBA{}
+
+	c1, c2 := l.NRGBA(), u.NRGBA()
+	t := (value - l.Quantity) / (u.Quantity - l.Quantity)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Max(0, math.Min(255, math.Round(float64(a)+(float64(b)-float64(a))*t))))
+	}
+
+	return color.NRGBA{R: lerp(c1.R, c2.R), G: lerp(c1.G, c2.G), B: lerp(c1.B, c2.B), A: lerp(c1.A, c2.A)}
 }
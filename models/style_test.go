@@ -0,0 +1,90 @@
+package models
+
+import (
+	"gotest.tools/v3/assert"
+	"image/color"
+	"math"
+	"testing"
+)
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+)
+
+func testStyle() RasterStyle {
+	return RasterStyle{
+		ColorMap: []ColorMapEntry{
+			{Color: "#ff0000", Quantity: 0, Opacity: 1},
+			{Color: "#00ff00", Quantity: 10, Opacity: 1},
+			{Color: "#0000ff", Quantity: 20, Opacity: 1},
+		},
+	}
+}
+
+func TestGetColor(t *testing.T) {
+	t.Run("INTERVALS CLOSED RIGHT", func(t *testing.T) {
+		style := testStyle()
+		assert.Equal(t, style.GetColor(0), red)
+		assert.Equal(t, style.GetColor(5), green)
+		assert.Equal(t, style.GetColor(10), green)
+		assert.Equal(t, style.GetColor(10.5), blue)
+		assert.Equal(t, style.GetColor(20), blue)
+	})
+
+	t.Run("INTERVALS CLOSED LEFT", func(t *testing.T) {
+		style := testStyle()
+		style.IntervalClosure = ClosedLeft
+		assert.Equal(t, style.GetColor(0), green)
+		assert.Equal(t, style.GetColor(9.5), green)
+		assert.Equal(t, style.GetColor(10), blue)
+		assert.Equal(t, style.GetColor(20), blue)
+	})
+
+	t.Run("CLAMP", func(t *testing.T) {
+		style := testStyle()
+		assert.Equal(t, style.GetColor(-100), red)
+		assert.Equal(t, style.GetColor(100), blue)
+
+		style.ColorMapType = ColorMapRamp
+		assert.Equal(t, style.GetColor(-100), red)
+		assert.Equal(t, style.GetColor(100), blue)
+	})
+
+	t.Run("TRANSPARENT", func(t *testing.T) {
+		style := testStyle()
+		style.Below = OutOfRangeTransparent
+		style.Above = OutOfRangeTransparent
+		assert.Equal(t, style.GetColor(-1), color.NRGBA{})
+		assert.Equal(t, style.GetColor(21), color.NRGBA{})
+		assert.Equal(t, style.GetColor(0), red)
+		assert.Equal(t, style.GetColor(20), blue)
+	})
+
+	t.Run("EXTEND", func(t *testing.T) {
+		style := testStyle()
+		style.ColorMapType = ColorMapRamp
+		style.Below = OutOfRangeExtend
+		style.Above = OutOfRangeExtend
+		assert.Equal(t, style.GetColor(5), color.NRGBA{R: 128, G: 128, A: 255})
+		assert.Equal(t, style.GetColor(-5), color.NRGBA{R: 255, A: 255})
+		assert.Equal(t, style.GetColor(25), color.NRGBA{B: 255, A: 255})
+
+		style.ColorMap[0].Opacity = 0.5
+		assert.Equal(t, style.GetColor(-5), color.NRGBA{R: 255, A: 65})
+	})
+
+	t.Run("VALUES", func(t *testing.T) {
+		style := testStyle()
+		style.ColorMapType = ColorMapValues
+		assert.Equal(t, style.GetColor(10), green)
+		assert.Equal(t, style.GetColor(11), color.NRGBA{})
+		assert.Equal(t, style.GetColor(-100), color.NRGBA{})
+	})
+
+	t.Run("NAN", func(t *testing.T) {
+		style := testStyle()
+		assert.Equal(t, style.GetColor(math.NaN()), color.NRGBA{})
+	})
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RenderMode defines what is rendered from a single band
+type RenderMode string
+
+const (
+	// ModeDefault renders the band through the color map, or as grayscale when there is none
+	ModeDefault RenderMode = "default"
+	// ModeHillshade renders the band as an elevation model lit by the sun, see Hillshade
+	ModeHillshade RenderMode = "hillshade"
+	// ModeSlope renders the steepness of the band as an elevation model, see Slope, through the color map if there is one
+	ModeSlope RenderMode = "slope"
+	// ModeAspect renders the compass direction the slopes of the band face, as an elevation model, from 0 to 360 degrees
+	// clockwise from north, through the color map if there is one. Flat areas have no aspect and are transparent.
+	ModeAspect RenderMode = "aspect"
+	// ModeRelief colors the band as an elevation model through the color map and blends its hillshade on top, see Relief
+	ModeRelief RenderMode = "relief"
+)
+
+// ParseRenderMode parses a raster-mode value. An empty value defaults to ModeDefault.
+func ParseRenderMode(value string) (RenderMode, error) {
+	switch m := RenderMode(value); m {
+	case "":
+		return ModeDefault, nil
+	case ModeDefault, ModeHillshade, ModeSlope, ModeAspect, ModeRelief:
+		return m, nil
+	}
+
+	return "", fmt.Errorf("invalid raster-mode %q: expected default, hillshade, slope, aspect or relief", value)
+}
+
+// Hillshade defines how an elevation model is lit, like the options of gdaldem hillshade
+// The zero value of every field stands for its value in DefaultHillshade.
+type Hillshade struct {
+	// Azimuth is the direction the light comes from, in degrees clockwise from north, 315 if 0. Use 360 for a light from the north
+	Azimuth float64
+	// Altitude is the angle of the light above the horizon, in degrees, 45 if 0
+	Altitude float64
+	// ZFactor exaggerates the elevations, 1 if 0
+	ZFactor float64
+	// Scale is the number of vertical units in a horizontal unit of the SRS, 1 if 0, e.g. 3.2808 for elevations in feet
+	// in a SRS in meters. Rasters rendered in a geographic SRS have their pixel sizes converted from degrees to meters.
+	Scale float64
+	// Multidirectional combines the light from 225, 270, 315 and 360 degrees like gdaldem -multidirectional, Azimuth is ignored
+	Multidirectional bool
+}
+
+// DefaultHillshade lights the elevation model from the north-west, 45 degrees above the horizon
+func DefaultHillshade() Hillshade {
+	return Hillshade{Azimuth: 315, Altitude: 45, ZFactor: 1, Scale: 1}
+}
+
+// WithDefaults returns the hillshade with its zero fields set as in DefaultHillshade.
+func (h Hillshade) WithDefaults() Hillshade {
+	defaults := DefaultHillshade()
+	if h.Azimuth == 0 {
+		h.Azimuth = defaults.Azimuth
+	}
+	if h.Altitude == 0 {
+		h.Altitude = defaults.Altitude
+	}
+	if h.ZFactor == 0 {
+		h.ZFactor = defaults.ZFactor
+	}
+	if h.Scale == 0 {
+		h.Scale = defaults.Scale
+	}
+
+	return h
+}
+
+// SlopeUnit is the unit of the steepness rendered by ModeSlope
+type SlopeUnit string
+
+const (
+	// SlopeDegrees measures the steepness as the angle from the horizontal, from 0 to 90
+	SlopeDegrees SlopeUnit = "degrees"
+	// SlopePercent measures the steepness as the rise over the run, times 100. 45 degrees is 100 percent
+	SlopePercent SlopeUnit = "percent"
+)
+
+// ParseSlopeUnit parses a raster-slope-unit value. An empty value defaults to SlopeDegrees.
+func ParseSlopeUnit(value string) (SlopeUnit, error) {
+	switch u := SlopeUnit(value); u {
+	case "":
+		return SlopeDegrees, nil
+	case SlopeDegrees, SlopePercent:
+		return u, nil
+	}
+
+	return "", fmt.Errorf("invalid raster-slope-unit %q: expected degrees or percent", value)
+}
+
+// Slope defines how the steepness of an elevation model is measured, like the options of gdaldem slope
+type Slope struct {
+	Unit SlopeUnit // degrees if empty
+	// ZFactor exaggerates the elevations, 1 if 0
+	ZFactor float64
+	// Scale is the number of vertical units in a horizontal unit of the SRS, 1 if 0, see Hillshade.Scale
+	Scale float64
+}
+
+// BlendMode defines how ModeRelief blends the hillshade with the colors of the elevations
+type BlendMode string
+
+const (
+	// BlendMultiply darkens the colors by the shade, the shadows are black
+	BlendMultiply BlendMode = "multiply"
+	// BlendOverlay darkens the colors in the shadows and lightens them on the slopes facing the light, keeping more of their contrast
+	BlendOverlay BlendMode = "overlay"
+)
+
+// ParseBlendMode parses a raster-relief-blend value. An empty value defaults to BlendMultiply.
+func ParseBlendMode(value string) (BlendMode, error) {
+	switch b := BlendMode(value); b {
+	case "":
+		return BlendMultiply, nil
+	case BlendMultiply, BlendOverlay:
+		return b, nil
+	}
+
+	return "", fmt.Errorf("invalid raster-relief-blend %q: expected multiply or overlay", value)
+}
+
+// Relief defines how ModeRelief blends the hillshade, lit as set by Hillshade, with the hypsometric tint of the color map
+type Relief struct {
+	Blend BlendMode // multiply if empty
+	// Strength of the hillshade, from above 0 (nearly the colors alone) to 1 (fully blended), 1 if 0
+	Strength float64
+}
+
+// DefaultRelief fully multiplies the colors by the hillshade
+func DefaultRelief() Relief {
+	return Relief{Blend: BlendMultiply, Strength: 1}
+}
+
+// WithDefaults returns the relief with its zero fields set as in DefaultRelief.
+func (r Relief) WithDefaults() Relief {
+	defaults := DefaultRelief()
+	if r.Blend == "" {
+		r.Blend = defaults.Blend
+	}
+	if r.Strength == 0 {
+		r.Strength = defaults.Strength
+	}
+
+	return r
+}
+
+// ParseDegrees parses an angle in degrees between min and max, for a raster-hillshade-* or raster-slope-* property.
+func ParseDegrees(property, value string, min, max float64) (float64, error) {
+	degrees, err := strconv.ParseFloat(value, 64)
+	if err != nil || degrees < min || degrees > max {
+		return 0, fmt.Errorf("invalid %s %q: expected degrees between %g and %g", property, value, min, max)
+	}
+
+	return degrees, nil
+}
+
+// ParseFraction parses a number above 0 and up to 1, for a raster-relief-* property.
+func ParseFraction(property, value string) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 || v > 1 {
+		return 0, fmt.Errorf("invalid %s %q: expected a number above 0 and up to 1", property, value)
+	}
+
+	return v, nil
+}
+
+// ParsePositive parses a number above 0, for a raster-hillshade-* or raster-slope-* property.
+func ParsePositive(property, value string) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a number above 0", property, value)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestParseRenderMode(t *testing.T) {
+	for value, expected := range map[string]RenderMode{"": ModeDefault, "default": ModeDefault, "hillshade": ModeHillshade, "slope": ModeSlope, "aspect": ModeAspect, "relief": ModeRelief} {
+		mode, err := ParseRenderMode(value)
+		assert.NilError(t, err)
+		assert.Equal(t, mode, expected)
+	}
+
+	_, err := ParseRenderMode("contour")
+	assert.ErrorContains(t, err, "invalid raster-mode")
+}
+
+func TestParseSlopeUnit(t *testing.T) {
+	for value, expected := range map[string]SlopeUnit{"": SlopeDegrees, "degrees": SlopeDegrees, "percent": SlopePercent} {
+		unit, err := ParseSlopeUnit(value)
+		assert.NilError(t, err)
+		assert.Equal(t, unit, expected)
+	}
+
+	_, err := ParseSlopeUnit("radians")
+	assert.ErrorContains(t, err, "invalid raster-slope-unit")
+}
+
+func TestHillshadeWithDefaults(t *testing.T) {
+	assert.Equal(t, Hillshade{}.WithDefaults(), DefaultHillshade())
+	assert.Equal(t, Hillshade{Azimuth: 360, ZFactor: 2, Multidirectional: true}.WithDefaults(),
+		Hillshade{Azimuth: 360, Altitude: 45, ZFactor: 2, Scale: 1, Multidirectional: true})
+}
+
+func TestReliefWithDefaults(t *testing.T) {
+	assert.Equal(t, Relief{}.WithDefaults(), DefaultRelief())
+	assert.Equal(t, Relief{Blend: BlendOverlay}.WithDefaults(), Relief{Blend: BlendOverlay, Strength: 1})
+}
+
+func TestParseBlendMode(t *testing.T) {
+	for value, expected := range map[string]BlendMode{"": BlendMultiply, "multiply": BlendMultiply, "overlay": BlendOverlay} {
+		blend, err := ParseBlendMode(value)
+		assert.NilError(t, err)
+		assert.Equal(t, blend, expected)
+	}
+
+	_, err := ParseBlendMode("screen")
+	assert.ErrorContains(t, err, "invalid raster-relief-blend")
+}
+
+func TestParseTerrainValues(t *testing.T) {
+	degrees, err := ParseDegrees("raster-hillshade-azimuth", "270", 0, 360)
+	assert.NilError(t, err)
+	assert.Equal(t, degrees, 270.0)
+
+	_, err = ParseDegrees("raster-hillshade-altitude", "91", 0, 90)
+	assert.ErrorContains(t, err, "invalid raster-hillshade-altitude")
+
+	v, err := ParsePositive("raster-hillshade-z-factor", "1.5")
+	assert.NilError(t, err)
+	assert.Equal(t, v, 1.5)
+
+	_, err = ParsePositive("raster-hillshade-scale", "0")
+	assert.ErrorContains(t, err, "invalid raster-hillshade-scale")
+
+	v, err = ParseFraction("raster-relief-strength", "0.6")
+	assert.NilError(t, err)
+	assert.Equal(t, v, 0.6)
+
+	for _, value := range []string{"1.5", "0"} {
+		_, err = ParseFraction("raster-relief-strength", value)
+		assert.ErrorContains(t, err, "invalid raster-relief-strength", value)
+	}
+}
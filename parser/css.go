@@ -2,8 +2,11 @@ package parser
 
 import (
 	"fmt"
+	"github.com/canghel3/raster2image/expr"
 	"github.com/canghel3/raster2image/models"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -23,17 +26,162 @@ func (cp *CSSParser) Parse() (*models.RasterStyle, error) {
 		return nil, err
 	}
 
-	style := &models.RasterStyle{}
+	style := &models.RasterStyle{Hillshade: models.DefaultHillshade(), Relief: models.DefaultRelief()}
 	lines := strings.Split(string(content), "\n")
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 
 		// Set raster channels
-		if strings.HasPrefix(line, "raster-channels") {
-			parts := strings.Split(line, ":")
-			if len(parts) > 1 {
-				style.RasterChannels = strings.TrimSuffix(strings.TrimSpace(parts[1]), ";")
+		if value, ok := property(line, "raster-channels"); ok {
+			style.RasterChannels = value
+			if _, err = models.ParseChannels(style.RasterChannels); err != nil {
+				return nil, err
+			}
+		}
+
+		// Set how values are matched against the color map
+		if value, ok := property(line, "raster-color-map-type"); ok {
+			style.ColorMapType, err = models.ParseColorMapType(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-color-map-below"); ok {
+			style.Below, err = models.ParseOutOfRange(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-color-map-above"); ok {
+			style.Above, err = models.ParseOutOfRange(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-color-map-closure"); ok {
+			style.IntervalClosure, err = models.ParseIntervalClosure(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Set how bands without a color map are stretched to grayscale
+		if value, ok := property(line, "raster-stretch"); ok {
+			curve, gamma := style.Stretch.Curve, style.Stretch.Gamma
+			style.Stretch, err = models.ParseStretch(value)
+			if err != nil {
+				return nil, err
+			}
+			style.Stretch.Curve, style.Stretch.Gamma = curve, gamma
+		}
+
+		if value, ok := property(line, "raster-stretch-curve"); ok {
+			style.Stretch.Curve, style.Stretch.Gamma, err = models.ParseStretchCurve(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Set what is rendered from a single band
+		if value, ok := property(line, "raster-mode"); ok {
+			style.Mode, err = models.ParseRenderMode(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-hillshade-azimuth"); ok {
+			style.Hillshade.Azimuth, err = models.ParseDegrees("raster-hillshade-azimuth", value, 0, 360)
+			if err != nil {
+				return nil, err
+			}
+			//a zero azimuth stands for the default one, 360 is the same north
+			if style.Hillshade.Azimuth == 0 {
+				style.Hillshade.Azimuth = 360
+			}
+		}
+
+		if value, ok := property(line, "raster-hillshade-altitude"); ok {
+			style.Hillshade.Altitude, err = models.ParseDegrees("raster-hillshade-altitude", value, 0, 90)
+			if err != nil {
+				return nil, err
+			}
+			if style.Hillshade.Altitude == 0 {
+				return nil, fmt.Errorf("invalid raster-hillshade-altitude %q: the light must be above the horizon", value)
+			}
+		}
+
+		if value, ok := property(line, "raster-hillshade-z-factor"); ok {
+			style.Hillshade.ZFactor, err = models.ParsePositive("raster-hillshade-z-factor", value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-hillshade-scale"); ok {
+			style.Hillshade.Scale, err = models.ParsePositive("raster-hillshade-scale", value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-hillshade-multidirectional"); ok {
+			style.Hillshade.Multidirectional, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid raster-hillshade-multidirectional %q: expected true or false", value)
+			}
+		}
+
+		if value, ok := property(line, "raster-color-ramp"); ok {
+			style.ColorRamp, err = models.ParseColorRamp(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-expression"); ok {
+			if _, err = expr.Parse(value); err != nil {
+				return nil, err
+			}
+			style.Expression = value
+		}
+
+		if value, ok := property(line, "raster-relief-blend"); ok {
+			style.Relief.Blend, err = models.ParseBlendMode(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-relief-strength"); ok {
+			style.Relief.Strength, err = models.ParseFraction("raster-relief-strength", value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-slope-unit"); ok {
+			style.Slope.Unit, err = models.ParseSlopeUnit(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-slope-z-factor"); ok {
+			style.Slope.ZFactor, err = models.ParsePositive("raster-slope-z-factor", value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if value, ok := property(line, "raster-slope-scale"); ok {
+			style.Slope.Scale, err = models.ParsePositive("raster-slope-scale", value)
+			if err != nil {
+				return nil, err
 			}
 		}
 
@@ -43,8 +191,8 @@ func (cp *CSSParser) Parse() (*models.RasterStyle, error) {
 			line = strings.TrimSuffix(line, ")")
 			parts := strings.Split(line, ",")
 
-			// Check for valid number of parts in the color-map-entry
-			if len(parts) != 4 {
+			// Check for valid number of parts in the color-map-entry: color, quantity and the optional opacity and label
+			if len(parts) < 2 || len(parts) > 4 {
 				return nil, fmt.Errorf("invalid color-map-entry format")
 			}
 
@@ -56,13 +204,19 @@ func (cp *CSSParser) Parse() (*models.RasterStyle, error) {
 				return nil, err
 			}
 
+			//an omitted or empty opacity is opaque
 			opacity := 1.0
-			_, err = fmt.Sscanf(strings.TrimSpace(parts[2]), "%f", &opacity)
-			if err != nil {
-				return nil, err
+			if len(parts) > 2 && strings.TrimSpace(parts[2]) != "" {
+				_, err = fmt.Sscanf(strings.TrimSpace(parts[2]), "%f", &opacity)
+				if err != nil {
+					return nil, err
+				}
 			}
 
-			label := strings.Trim(strings.TrimSpace(parts[3]), `"`)
+			var label string
+			if len(parts) > 3 {
+				label = strings.Trim(strings.TrimSpace(parts[3]), `"`)
+			}
 
 			// Append new color map entry
 			style.ColorMap = append(style.ColorMap, models.ColorMapEntry{
@@ -74,5 +228,24 @@ func (cp *CSSParser) Parse() (*models.RasterStyle, error) {
 		}
 	}
 
+	if style.ColorRamp.Name != "" && len(style.ColorMap) > 0 {
+		return nil, fmt.Errorf("raster-color-ramp cannot be used with color-map-entry")
+	}
+
+	//the color lookup relies on the entries being in ascending order, entries with the same quantity keep theirs
+	sort.SliceStable(style.ColorMap, func(i, j int) bool {
+		return style.ColorMap[i].Quantity < style.ColorMap[j].Quantity
+	})
+
 	return style, nil
 }
+
+// property returns the value of a "name: value;" declaration if the line declares the given property.
+func property(line, name string) (string, bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) != name {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(parts[1]), ";"), true
+}
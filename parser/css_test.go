@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"github.com/canghel3/raster2image/models"
 	"gotest.tools/v3/assert"
+	"image/color"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -16,3 +20,180 @@ func TestCSSParser(t *testing.T) {
 	assert.Assert(t, style.RasterChannels == "auto")
 	assert.Assert(t, len(style.ColorMap) == 11)
 }
+
+func TestCSSParserColorMapEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entries.css")
+	css := `raster {
+    raster-color-map:
+        color-map-entry(#000000, 0)
+        color-map-entry(#FF0000, 10, , "red")
+        color-map-entry(#00FF00, 20, 0.5)
+        color-map-entry(#0000FF, 30, 0, "hidden")
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, style.ColorMap, []models.ColorMapEntry{
+		{Color: "#000000", Quantity: 0, Opacity: 1},
+		{Color: "#FF0000", Quantity: 10, Opacity: 1, Label: "red"},
+		{Color: "#00FF00", Quantity: 20, Opacity: 0.5},
+		{Color: "#0000FF", Quantity: 30, Opacity: 0, Label: "hidden"},
+	})
+
+	assert.NilError(t, os.WriteFile(path, []byte("color-map-entry(#000000)\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid color-map-entry")
+}
+
+func TestCSSParserColorMapOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.css")
+	css := `raster {
+    raster-color-map:
+        color-map-entry(#0000FF, 30, 1, "high")
+        color-map-entry(#000000, 0, 1, "none")
+        color-map-entry(#FF0000, 10, 1, "low")
+        color-map-entry(#00FF00, 10, 1, "low too")
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+
+	var labels []string
+	for _, entry := range style.ColorMap {
+		labels = append(labels, entry.Label)
+	}
+	assert.DeepEqual(t, labels, []string{"none", "low", "low too", "high"})
+	assert.Equal(t, style.GetColor(5), color.NRGBA{R: 255, A: 255})
+}
+
+func TestCSSParserStretch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stretch.css")
+	css := "raster {\n    raster-stretch-curve: gamma 1.5;\n    raster-stretch: percentile 1 99;\n}\n"
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Stretch, models.Stretch{Type: models.StretchPercentile, Low: 1, High: 99, Curve: models.CurveGamma, Gamma: 1.5})
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-stretch: percentile 99 1;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid raster-stretch")
+}
+
+func TestCSSParserHillshade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hillshade.css")
+	css := `raster {
+    raster-mode: hillshade;
+    raster-hillshade-altitude: 30;
+    raster-hillshade-z-factor: 2;
+    raster-hillshade-multidirectional: true;
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Mode, models.ModeHillshade)
+	assert.Equal(t, style.Hillshade, models.Hillshade{Azimuth: 315, Altitude: 30, ZFactor: 2, Scale: 1, Multidirectional: true})
+	assert.Equal(t, style.Relief, models.DefaultRelief())
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-hillshade-azimuth: north;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid raster-hillshade-azimuth")
+
+	//0 is north, not the default azimuth
+	assert.NilError(t, os.WriteFile(path, []byte("raster-hillshade-azimuth: 0;\n"), 0644))
+	style, err = NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Hillshade.WithDefaults().Azimuth, 360.0)
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-hillshade-altitude: 0;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid raster-hillshade-altitude")
+}
+
+func TestCSSParserSlope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slope.css")
+	css := `raster {
+    raster-mode: slope;
+    raster-slope-unit: percent;
+    raster-slope-scale: 3.2808;
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Mode, models.ModeSlope)
+	assert.Equal(t, style.Slope, models.Slope{Unit: models.SlopePercent, Scale: 3.2808})
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-slope-z-factor: -1;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid raster-slope-z-factor")
+}
+
+func TestCSSParserRelief(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relief.css")
+	css := `raster {
+    raster-mode: relief;
+    raster-relief-blend: overlay;
+    raster-relief-strength: 0.6;
+    raster-color-map:
+            color-map-entry(#00FF00, 0, 1, "Lowlands")
+            color-map-entry(#FFFFFF, 3000, 1, "Peaks");
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Mode, models.ModeRelief)
+	assert.Equal(t, style.Relief, models.Relief{Blend: models.BlendOverlay, Strength: 0.6})
+	assert.Equal(t, len(style.ColorMap), 2)
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-relief-strength: 2;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid raster-relief-strength")
+}
+
+func TestCSSParserExpression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ndvi.css")
+	assert.NilError(t, os.WriteFile(path, []byte("raster {\n    raster-expression: ndvi(b4, b3);\n}\n"), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.Expression, "ndvi(b4, b3)")
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-expression: (b4 - b3;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "invalid expression")
+}
+
+func TestCSSParserColorRamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ramp.css")
+	assert.NilError(t, os.WriteFile(path, []byte("raster {\n    raster-color-ramp: RdBu -1 1 reverse;\n}\n"), 0644))
+
+	style, err := NewCSSParser(path).Parse()
+	assert.NilError(t, err)
+	assert.Equal(t, style.ColorRamp.Name, "rdbu")
+	assert.Assert(t, style.ColorRamp.Reverse)
+	assert.Equal(t, len(style.ColorMap), 0)
+
+	css := `raster {
+    raster-color-ramp: viridis;
+    raster-color-map:
+            color-map-entry(#000000, 1, 1, "None");
+}
+`
+	assert.NilError(t, os.WriteFile(path, []byte(css), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "raster-color-ramp cannot be used with color-map-entry")
+
+	assert.NilError(t, os.WriteFile(path, []byte("raster-color-ramp: rainbow;\n"), 0644))
+	_, err = NewCSSParser(path).Parse()
+	assert.ErrorContains(t, err, "unknown raster-color-ramp")
+}
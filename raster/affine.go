@@ -0,0 +1,55 @@
+package raster
+
+import "math"
+
+// geoTransform is the affine transform from the pixel coordinates of a raster to coordinates in its SRS, as GDAL defines it:
+// x = gt[0] + px*gt[1] + py*gt[2] and y = gt[3] + px*gt[4] + py*gt[5].
+type geoTransform [6]float64
+
+// apply converts the pixel coordinates px, py to coordinates in the SRS of the raster.
+func (gt geoTransform) apply(px, py float64) (x, y float64) {
+	return gt[0] + px*gt[1] + py*gt[2], gt[3] + px*gt[4] + py*gt[5]
+}
+
+// invert returns the transform from coordinates in the SRS of the raster to pixel coordinates.
+// It returns false when gt cannot be inverted, i.e. when it maps the raster to a line or a point.
+func (gt geoTransform) invert() (geoTransform, bool) {
+	det := gt[1]*gt[5] - gt[2]*gt[4]
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return geoTransform{}, false
+	}
+
+	inverse := geoTransform{
+		0, gt[5] / det, -gt[2] / det,
+		0, -gt[4] / det, gt[1] / det,
+	}
+	inverse[0] = -gt[0]*inverse[1] - gt[3]*inverse[2]
+	inverse[3] = -gt[0]*inverse[4] - gt[3]*inverse[5]
+
+	return inverse, true
+}
+
+// northUp reports whether the rows of the raster run from north to south and its columns from west to east,
+// without rotation or shear, so that a bbox is a window of pixels.
+func (gt geoTransform) northUp() bool {
+	return gt[2] == 0 && gt[4] == 0 && gt[1] > 0 && gt[5] < 0
+}
+
+// bounds returns the smallest bbox containing the raster of sizeX x sizeY pixels, which may be rotated or sheared.
+func (gt geoTransform) bounds(sizeX, sizeY int) [4]float64 {
+	return transformBounds(gt, [4]float64{0, 0, float64(sizeX), float64(sizeY)})
+}
+
+// transformBounds returns the smallest bbox containing the rectangle bounds once its corners are transformed by gt.
+func transformBounds(gt geoTransform, bounds [4]float64) [4]float64 {
+	transformed := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, corner := range [4][2]float64{{bounds[0], bounds[1]}, {bounds[2], bounds[1]}, {bounds[0], bounds[3]}, {bounds[2], bounds[3]}} {
+		x, y := gt.apply(corner[0], corner[1])
+		transformed[0] = math.Min(transformed[0], x)
+		transformed[1] = math.Min(transformed[1], y)
+		transformed[2] = math.Max(transformed[2], x)
+		transformed[3] = math.Max(transformed[3], y)
+	}
+
+	return transformed
+}
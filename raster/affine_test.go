@@ -0,0 +1,47 @@
+package raster
+
+import (
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestGeoTransform(t *testing.T) {
+	transforms := map[string]geoTransform{
+		"NORTH UP": {100, 10, 0, 500, 0, -10},
+		"ROTATED":  {100, 10 * math.Cos(0.5), -10 * math.Sin(0.5), 500, -10 * math.Sin(0.5), -10 * math.Cos(0.5)},
+		"SHEARED":  {100, 10, 3, 500, 2, -10},
+	}
+
+	for name, gt := range transforms {
+		t.Run(name, func(t *testing.T) {
+			inverse, ok := gt.invert()
+			assert.Assert(t, ok)
+
+			for _, pixel := range [][2]float64{{0, 0}, {3.5, 7.25}, {-2, 40}} {
+				x, y := gt.apply(pixel[0], pixel[1])
+				px, py := inverse.apply(x, y)
+				assert.Assert(t, math.Abs(px-pixel[0]) < 1e-9 && math.Abs(py-pixel[1]) < 1e-9, "%v became %v, %v", pixel, px, py)
+			}
+		})
+	}
+
+	t.Run("NORTH UP", func(t *testing.T) {
+		assert.Check(t, transforms["NORTH UP"].northUp())
+		assert.Check(t, !transforms["ROTATED"].northUp())
+		assert.Check(t, !transforms["SHEARED"].northUp())
+		//south-up
+		assert.Check(t, !geoTransform{100, 10, 0, 500, 0, 10}.northUp())
+	})
+
+	t.Run("BOUNDS", func(t *testing.T) {
+		assert.Equal(t, transforms["NORTH UP"].bounds(4, 2), [4]float64{100, 480, 140, 500})
+		//the corners of a sheared raster are not those of its first row and column
+		assert.Equal(t, transforms["SHEARED"].bounds(4, 2), [4]float64{100, 480, 146, 508})
+	})
+
+	t.Run("NOT INVERTIBLE", func(t *testing.T) {
+		_, ok := geoTransform{0, 10, 20, 0, 1, 2}.invert()
+		assert.Assert(t, !ok)
+	})
+}
@@ -0,0 +1,196 @@
+package raster
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/canghel3/raster2image/models"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pamDataset is the part of the .aux.xml sidecar GDAL writes next to a raster that holds the names of the values of its bands.
+// GeoTIFFs cannot store raster attribute tables nor category names, so GDAL keeps them there.
+type pamDataset struct {
+	Bands []pamBand `xml:"PAMRasterBand"`
+}
+
+type pamBand struct {
+	Band           int                      `xml:"band,attr"`
+	CategoryNames  []string                 `xml:"CategoryNames>Category"`
+	AttributeTable *pamRasterAttributeTable `xml:"GDALRasterAttributeTable"`
+}
+
+type pamRasterAttributeTable struct {
+	Row0Min *float64      `xml:"Row0Min,attr"`
+	BinSize *float64      `xml:"BinSize,attr"`
+	Fields  []pamFieldDef `xml:"FieldDefn"`
+	Rows    []pamRow      `xml:"Row"`
+}
+
+type pamFieldDef struct {
+	Index int    `xml:"index,attr"`
+	Name  string `xml:"Name"`
+	Type  int    `xml:"Type"`
+	Usage int    `xml:"Usage"`
+}
+
+type pamRow struct {
+	Index  int      `xml:"index,attr"`
+	Values []string `xml:"F"`
+}
+
+// field types and usages of GDAL raster attribute tables, see GDALRATFieldType and GDALRATFieldUsage
+const (
+	fieldReal   = 1
+	fieldString = 2
+
+	usageName   = 2
+	usageMinMax = 5
+	usageRed    = 6
+	usageGreen  = 7
+	usageBlue   = 8
+	usageAlpha  = 9
+)
+
+// categories returns the named values of the band at index i, read from the .aux.xml sidecar of the raster.
+// The raster attribute table takes precedence over the category names. Categories are colored like the color table of the band,
+// or like the color columns of the attribute table when the band has no color table.
+func (td *TifDriver) categories(i int) ([]models.Category, error) {
+	content, err := os.ReadFile(td.name + ".aux.xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pam pamDataset
+	if err = xml.Unmarshal(content, &pam); err != nil {
+		return nil, fmt.Errorf("invalid %s.aux.xml: %w", td.name, err)
+	}
+
+	var categories []models.Category
+	for _, band := range pam.Bands {
+		if band.Band != i+1 {
+			continue
+		}
+
+		if band.AttributeTable != nil {
+			categories = band.AttributeTable.categories()
+		} else {
+			for value, name := range band.CategoryNames {
+				if name != "" {
+					categories = append(categories, models.Category{Value: float64(value), Name: name})
+				}
+			}
+		}
+	}
+
+	if table, ok := td.colorTable(i); ok {
+		for j, category := range categories {
+			if color, ok := paletteColor(table, category.Value); ok {
+				categories[j].Color = color
+			}
+		}
+	}
+
+	return categories, nil
+}
+
+// categories returns the rows of the table which have a name. The value of a row is its MinMax column, or its Value column,
+// or the value of its bin when the table is binned linearly, or its index.
+func (rat *pamRasterAttributeTable) categories() []models.Category {
+	name, value, colors := -1, -1, [4]int{-1, -1, -1, -1}
+	for _, field := range rat.Fields {
+		switch {
+		case field.Usage == usageName && name < 0:
+			name = field.Index
+		case field.Usage == usageMinMax:
+			value = field.Index
+		case field.Usage >= usageRed && field.Usage <= usageAlpha:
+			colors[field.Usage-usageRed] = field.Index
+		case strings.EqualFold(field.Name, "value") && field.Type != fieldString && value < 0:
+			value = field.Index
+		}
+	}
+
+	//tables without a Name column often name their classes in their first string column
+	for _, field := range rat.Fields {
+		if name < 0 && field.Type == fieldString {
+			name = field.Index
+		}
+	}
+	if name < 0 {
+		return nil
+	}
+
+	var categories []models.Category
+	for _, row := range rat.Rows {
+		category := models.Category{Value: float64(row.Index), Name: row.field(name)}
+		if category.Name == "" {
+			continue
+		}
+
+		switch {
+		case value >= 0:
+			v, err := strconv.ParseFloat(row.field(value), 64)
+			if err != nil {
+				continue
+			}
+			category.Value = v
+		case rat.Row0Min != nil && rat.BinSize != nil:
+			category.Value = *rat.Row0Min + float64(row.Index)**rat.BinSize
+		}
+
+		if colors[0] >= 0 && colors[1] >= 0 && colors[2] >= 0 {
+			category.Color = rat.color(row, colors)
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories
+}
+
+// color returns the hex color of the row from its Red, Green, Blue and, optionally, Alpha columns,
+// which are integers from 0 to 255 or reals from 0 to 1.
+func (rat *pamRasterAttributeTable) color(row pamRow, columns [4]int) string {
+	var components [4]uint8
+	components[3] = 255
+	for c, column := range columns {
+		if column < 0 {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(row.field(column), 64)
+		if err != nil {
+			return ""
+		}
+		if rat.fieldType(column) == fieldReal {
+			v *= 255
+		}
+		components[c] = uint8(max(0, min(255, v+0.5)))
+	}
+
+	return hexColor(components[0], components[1], components[2], components[3])
+}
+
+func (rat *pamRasterAttributeTable) fieldType(index int) int {
+	for _, field := range rat.Fields {
+		if field.Index == index {
+			return field.Type
+		}
+	}
+	return 0
+}
+
+// field returns the value of the column at index i of the row, empty if the row is too short.
+func (row pamRow) field(i int) string {
+	if i < 0 || i >= len(row.Values) {
+		return ""
+	}
+	return strings.TrimSpace(row.Values[i])
+}
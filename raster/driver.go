@@ -1,12 +1,28 @@
 package raster
 
 import (
+	"errors"
 	"github.com/canghel3/raster2image/models"
+	"github.com/canghel3/raster2image/stats"
 	"image"
 )
 
+// ErrOutsideExtent is returned by renders of a bbox which does not overlap the raster, when WithOutsideExtentError is used.
+var ErrOutsideExtent = errors.New("requested area is outside the raster extent")
+
 type Driver interface {
-	Render(bbox [4]float64, width, height uint) (image.Image, error)
+	Render(bbox [4]float64, width, height uint, options ...RenderOption) (image.Image, error)
+	RenderTile(z, x, y uint, options ...RenderOption) (image.Image, error)
+	Info() (*models.RasterInfo, error)
+	Statistics(band int, options ...StatisticsOption) (*stats.Statistics, error)
 	Release() error
 	setStyle(style *models.RasterStyle)
+	setNoData(noData float64)
+	setOverviews(config overviewConfig)
+	setExpression(source string)
+	compileExpression() error
+	expandColorRamp() error
+	readPalettes() error
+	buildOverviews() error
+	computeRanges() error
 }
@@ -0,0 +1,123 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/canghel3/raster2image/expr"
+	"github.com/canghel3/raster2image/render"
+	"github.com/canghel3/raster2image/stats"
+	"image"
+)
+
+// expressionBand is the index that stands for the expression instead of a band, for stretch
+const expressionBand = -1
+
+func (td *TifDriver) setExpression(source string) {
+	td.expressionSource = source
+}
+
+// compileExpression parses the expression of WithExpression, or the style's raster-expression when there is none,
+// and checks that the bands it reads exist.
+func (td *TifDriver) compileExpression() error {
+	source := td.expressionSource
+	if source == "" && td.style != nil {
+		source = td.style.Expression
+	}
+	if source == "" {
+		return nil
+	}
+
+	expression, err := expr.Parse(source)
+	if err != nil {
+		return err
+	}
+
+	bandCount := len(td.dataset.Bands())
+	bands := expression.Bands()
+	if len(bands) == 0 {
+		return fmt.Errorf("expression %q does not read any band of raster %s", source, td.name)
+	}
+	if last := bands[len(bands)-1]; last > bandCount {
+		return fmt.Errorf("band %d of expression %q does not exist in raster %s with %d Bands", last, source, td.name, bandCount)
+	}
+
+	td.expression = expression
+	return nil
+}
+
+// renderExpression renders the expression computed from the warped bands, with the style's color map if there is one,
+// or as grayscale otherwise. Pixels where any band read by the expression is NoData and pixels outside the raster are transparent.
+func (td *TifDriver) renderExpression(bbox [4]float64, width, height uint, ro *renderOptions) (image.Image, error) {
+	bands := td.expression.Bands()
+	data, coverage, err := td.fetch(bbox, width, height, ro, bands...)
+	if err != nil {
+		return nil, err
+	}
+
+	//the warp keeps the NoData values of every band, only the pixels that are NoData in all of them are uncovered
+	for i, band := range bands {
+		td.noDataToNaN(band-1, data[i])
+	}
+	values := td.expression.EvaluateBands(data)
+
+	var drawer render.Drawer
+	if td.style != nil && len(td.style.ColorMap) > 0 {
+		drawer = render.NewRGBDrawer(values, int(width), int(height), render.StyleOption(*td.style))
+	} else {
+		stretch, err := td.stretch(expressionBand, ro)
+		if err != nil {
+			return nil, err
+		}
+		drawer = render.StretchedGrayscale(values, int(width), int(height), stretch)
+	}
+
+	if mask, ok := validityMask(coverage, values); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
+	}
+
+	return drawer.Draw()
+}
+
+// expressionStatistics computes the statistics of the expression over the whole raster, like Statistics does for a band.
+func (td *TifDriver) expressionStatistics(options ...StatisticsOption) (*stats.Statistics, error) {
+	so := newStatisticsOptions(options...)
+	key := fmt.Sprintf("expression %t %d %v", so.approximate, so.bins, so.percentiles)
+	if cached, ok := td.statistics.Load(key); ok {
+		return cached.(*stats.Statistics), nil
+	}
+
+	var statistics stats.Statistics
+	bands := td.expression.Bands()
+	if so.approximate && td.sampled() {
+		data := make([][]float64, len(bands))
+		for i, band := range bands {
+			var err error
+			data[i], err = td.readSample(band - 1)
+			if err != nil {
+				return nil, err
+			}
+			td.noDataToNaN(band-1, data[i])
+		}
+		statistics = stats.Compute(td.expression.EvaluateBands(data), stats.Bins(so.bins), stats.Percentiles(so.percentiles...))
+	} else {
+		indexes := make([]int, len(bands))
+		for i, band := range bands {
+			indexes[i] = band - 1
+		}
+
+		var err error
+		statistics, err = stats.ComputeSource(func(yield func(block []float64) error) error {
+			return td.readBlocks(indexes, func(data [][]float64) error {
+				for i, index := range indexes {
+					td.noDataToNaN(index, data[i])
+				}
+				return yield(td.expression.EvaluateBands(data))
+			})
+		}, stats.Bins(so.bins), stats.Percentiles(so.percentiles...))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cached, _ := td.statistics.LoadOrStore(key, &statistics)
+	return cached.(*stats.Statistics), nil
+}
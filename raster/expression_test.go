@@ -0,0 +1,89 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderExpression(t *testing.T) {
+	const width, height = 4, 1
+
+	//the first band is red, the second near infrared, -1 is NoData
+	red := []float64{1, 3, 2, -1}
+	nir := []float64{3, 1, 2, 5}
+	path := createTestRaster(t, "bands.tif", godal.Float32, width, height, red, nir)
+
+	driver, err := Load(path, WithNoData(-1), WithExpression("ndvi(b2, b1)"))
+	assert.NilError(t, err)
+	defer driver.Release()
+
+	t.Run("GRAYSCALE", func(t *testing.T) {
+		//stretched from the min of the expression, -0.5, to its max, 0.5
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), gray(255))
+		assert.Equal(t, nrgba(img, 1, 0), gray(0))
+		assert.Equal(t, nrgba(img, 2, 0), gray(128))
+		assert.Equal(t, nrgba(img, 3, 0).A, uint8(0))
+	})
+
+	t.Run("COLOR MAP", func(t *testing.T) {
+		driver.(*TifDriver).setStyle(&models.RasterStyle{
+			ColorMap: []models.ColorMapEntry{
+				{Color: "#FF0000", Quantity: 0, Opacity: 1},
+				{Color: "#00FF00", Quantity: 1, Opacity: 1},
+			},
+		})
+		defer driver.(*TifDriver).setStyle(nil)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{G: 255, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{R: 255, A: 255})
+	})
+
+	t.Run("STATISTICS", func(t *testing.T) {
+		statistics, err := driver.(*TifDriver).expressionStatistics()
+		assert.NilError(t, err)
+		assert.Equal(t, statistics.Count, 3)
+		assert.Equal(t, statistics.Min, -0.5)
+		assert.Equal(t, statistics.Max, 0.5)
+	})
+
+	t.Run("MODE", func(t *testing.T) {
+		_, err := driver.Render(testBBox(width, height), width, height, WithHillshade(models.DefaultHillshade()))
+		assert.ErrorContains(t, err, "cannot render the expression")
+	})
+
+	t.Run("STYLE", func(t *testing.T) {
+		style := filepath.Join(t.TempDir(), "ratio.css")
+		assert.NilError(t, os.WriteFile(style, []byte("raster {\n    raster-expression: b2 / b1;\n}\n"), 0644))
+
+		driver, err := Load(path, WithStyle(style))
+		assert.NilError(t, err)
+		defer driver.Release()
+		assert.Equal(t, driver.(*TifDriver).expression.String(), "b2 / b1")
+
+		//the load option takes precedence
+		driver, err = Load(path, WithStyle(style), WithExpression("b1 - b2"))
+		assert.NilError(t, err)
+		defer driver.Release()
+		assert.Equal(t, driver.(*TifDriver).expression.String(), "b1 - b2")
+	})
+
+	t.Run("INVALID", func(t *testing.T) {
+		_, err := Load(path, WithExpression("b3 - b1"))
+		assert.ErrorContains(t, err, "band 3 of expression")
+
+		_, err = Load(path, WithExpression("1 + 1"))
+		assert.ErrorContains(t, err, "does not read any band")
+
+		_, err = Load(path, WithExpression("ndvi(b2)"))
+		assert.ErrorContains(t, err, "invalid expression")
+	})
+}
@@ -0,0 +1,122 @@
+package raster
+
+import (
+	"errors"
+	"github.com/airbusgeo/godal"
+	"math"
+)
+
+// extentDensity is the number of points per edge used to reproject an extent, so that edges which curve in the target SRS
+// are still covered.
+const extentDensity = 21
+
+// overlaps reports whether the bbox overlaps the extent of the raster, in the bbox SRS of the render options.
+func (td *TifDriver) overlaps(bbox [4]float64, ro *renderOptions) (bool, error) {
+	extent, err := td.extent(ro.bboxSRS)
+	if err != nil {
+		return false, err
+	}
+
+	return bbox[0] < extent[2] && bbox[2] > extent[0] && bbox[1] < extent[3] && bbox[3] > extent[1], nil
+}
+
+// extent returns the bounds of the raster in the given SRS.
+func (td *TifDriver) extent(srs string) ([4]float64, error) {
+	if extent, ok := td.extentCache.Load(srs); ok {
+		return extent.([4]float64), nil
+	}
+
+	td.lock.RLock()
+	gt, err := td.dataset.GeoTransform()
+	structure := td.dataset.Structure()
+	td.lock.RUnlock()
+	if err != nil {
+		return [4]float64{}, err
+	}
+
+	//every corner counts, the raster may be rotated or sheared
+	bounds := geoTransform(gt).bounds(structure.SizeX, structure.SizeY)
+
+	if !td.isDatasetSRS(srs) {
+		own, err := td.spatialRef()
+		if err != nil {
+			return [4]float64{}, err
+		}
+		defer own.Close()
+
+		target, err := godal.NewSpatialRef(srs)
+		if err != nil {
+			return [4]float64{}, err
+		}
+		defer target.Close()
+
+		bounds, err = reprojectBounds(bounds, own, target)
+		if err != nil {
+			return [4]float64{}, err
+		}
+	}
+
+	td.extentCache.Store(srs, bounds)
+	return bounds, nil
+}
+
+// spatialRef returns the SRS of the dataset, DefaultSRS for datasets without one. The caller is responsible for closing it.
+func (td *TifDriver) spatialRef() (*godal.SpatialRef, error) {
+	td.lock.RLock()
+	defer td.lock.RUnlock()
+
+	if td.dataset.Projection() == "" {
+		return godal.NewSpatialRef(DefaultSRS)
+	}
+
+	return td.dataset.SpatialRef(), nil
+}
+
+// reprojectBounds returns the bounds in the target SRS of the area covered by bounds in the source SRS.
+// Points that cannot be reprojected, e.g. beyond the valid area of the target SRS, are left out.
+func reprojectBounds(bounds [4]float64, source, target *godal.SpatialRef) ([4]float64, error) {
+	transform, err := godal.NewTransform(source, target)
+	if err != nil {
+		return [4]float64{}, err
+	}
+	defer transform.Close()
+
+	x, y := boundsOutline(bounds, extentDensity)
+	successful := make([]bool, len(x))
+	err = transform.TransformEx(x, y, nil, successful)
+	if err != nil {
+		return [4]float64{}, err
+	}
+
+	reprojected := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for i := range x {
+		if !successful[i] {
+			continue
+		}
+
+		reprojected[0] = math.Min(reprojected[0], x[i])
+		reprojected[1] = math.Min(reprojected[1], y[i])
+		reprojected[2] = math.Max(reprojected[2], x[i])
+		reprojected[3] = math.Max(reprojected[3], y[i])
+	}
+
+	if math.IsInf(reprojected[0], 0) {
+		return [4]float64{}, errors.New("cannot reproject the raster extent")
+	}
+
+	return reprojected, nil
+}
+
+// boundsOutline returns density points on each edge of bounds, corners included.
+func boundsOutline(bounds [4]float64, density int) (x, y []float64) {
+	for i := 0; i < density; i++ {
+		f := float64(i) / float64(density-1)
+		dx := bounds[0] + f*(bounds[2]-bounds[0])
+		dy := bounds[1] + f*(bounds[3]-bounds[1])
+
+		x = append(x, dx, dx, bounds[0], bounds[2])
+		y = append(y, bounds[1], bounds[3], dy, dy)
+	}
+
+	return x, y
+}
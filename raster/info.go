@@ -0,0 +1,120 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"sort"
+	"strconv"
+)
+
+// wgs84 is the SRS of RasterInfo.WGS84Extent
+const wgs84 = "EPSG:4326"
+
+// Info describes the raster: its size, bands, georeferencing, extents, overviews, the min and max used to stretch its bands
+// and the names of the values of classified bands, to build legends from.
+// Rasters without an SRS are described in EPSG:3857, which is the SRS they are rendered from.
+func (td *TifDriver) Info() (*models.RasterInfo, error) {
+	sr, err := td.spatialRef()
+	if err != nil {
+		return nil, err
+	}
+	defer sr.Close()
+
+	srs, err := srsInfo(sr)
+	if err != nil {
+		return nil, err
+	}
+
+	extent, err := td.extent(srs.WKT)
+	if err != nil {
+		return nil, err
+	}
+
+	wgs84Extent, err := td.extent(wgs84)
+	if err != nil {
+		return nil, err
+	}
+
+	//read before locking, since the color tables that color the categories are read under the lock
+	categories := make([][]models.Category, len(td.dataset.Bands()))
+	for i := range categories {
+		categories[i], err = td.categories(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	td.lock.RLock()
+	defer td.lock.RUnlock()
+
+	gt, err := td.dataset.GeoTransform()
+	if err != nil {
+		return nil, err
+	}
+
+	structure := td.dataset.Structure()
+	info := &models.RasterInfo{
+		Name:         td.name,
+		Width:        structure.SizeX,
+		Height:       structure.SizeY,
+		GeoTransform: gt,
+		SRS:          srs,
+		Extent:       extent,
+		WGS84Extent:  wgs84Extent,
+		Overviews:    []int{},
+	}
+
+	bands := td.dataset.Bands()
+	for level := range existingOverviewLevels(bands[0]) {
+		info.Overviews = append(info.Overviews, level)
+	}
+	sort.Ints(info.Overviews)
+
+	for i, band := range bands {
+		min, max := td.bandRange(i)
+		bandInfo := models.BandInfo{
+			Index:               i + 1,
+			DataType:            band.Structure().DataType.String(),
+			ColorInterpretation: band.ColorInterp().Name(),
+			Min:                 min,
+			Max:                 max,
+			Categories:          categories[i],
+		}
+
+		if noData, ok := td.bandNoData(i); ok {
+			value := models.JSONFloat(noData)
+			bandInfo.NoData = &value
+		}
+
+		info.Bands = append(info.Bands, bandInfo)
+	}
+
+	return info, nil
+}
+
+// srsInfo returns the WKT of sr and its EPSG code, when it has one or GDAL can identify it.
+func srsInfo(sr *godal.SpatialRef) (models.SRSInfo, error) {
+	wkt, err := sr.WKT()
+	if err != nil {
+		return models.SRSInfo{}, err
+	}
+
+	//identifying the EPSG code changes the SRS, so it is done on a copy
+	identified, err := godal.NewSpatialRefFromWKT(wkt)
+	if err != nil {
+		return models.SRSInfo{}, err
+	}
+	defer identified.Close()
+
+	if identified.AuthorityName("") != "EPSG" {
+		//not every SRS has an EPSG equivalent
+		_ = identified.AutoIdentifyEPSG()
+	}
+
+	info := models.SRSInfo{WKT: wkt}
+	if identified.AuthorityName("") == "EPSG" {
+		info.EPSG, _ = strconv.Atoi(identified.AuthorityCode(""))
+	}
+
+	return info, nil
+}
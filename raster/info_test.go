@@ -0,0 +1,58 @@
+package raster
+
+import (
+	"encoding/json"
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestInfo(t *testing.T) {
+	const width, height = 4, 2
+	path := createTestRasterInSRS(t, "info.tif", 4326, godal.Int16, width, height, ramp(width*height, -5, 1), ramp(width*height, 10, 10))
+	driver, err := Load(path, WithNoData(-5))
+	assert.NilError(t, err)
+
+	info, err := driver.Info()
+	assert.NilError(t, err)
+
+	assert.Equal(t, info.Name, path)
+	assert.Equal(t, info.Width, width)
+	assert.Equal(t, info.Height, height)
+	assert.Equal(t, info.GeoTransform, [6]float64{0, testPixelSize, 0, height * testPixelSize, 0, -testPixelSize})
+	assert.Equal(t, info.SRS.EPSG, 4326)
+	assert.Equal(t, info.Extent, [4]float64{0, 0, width * testPixelSize, height * testPixelSize})
+	assert.Equal(t, info.WGS84Extent, info.Extent)
+	assert.DeepEqual(t, info.Overviews, []int{})
+
+	noData := models.JSONFloat(-5)
+	assert.DeepEqual(t, info.Bands, []models.BandInfo{
+		{Index: 1, DataType: "Int16", ColorInterpretation: "Gray", NoData: &noData, Min: -4, Max: 2},
+		{Index: 2, DataType: "Int16", ColorInterpretation: "Undefined", NoData: &noData, Min: 10, Max: 80},
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(info)
+		assert.NilError(t, err)
+
+		var parsed models.RasterInfo
+		assert.NilError(t, json.Unmarshal(data, &parsed))
+		assert.DeepEqual(t, &parsed, info)
+	})
+
+	t.Run("WGS84 EXTENT", func(t *testing.T) {
+		path := createTestRaster(t, "info3857.tif", godal.Byte, width, height, ramp(width*height, 0, 1))
+		driver, err := Load(path)
+		assert.NilError(t, err)
+
+		info, err := driver.Info()
+		assert.NilError(t, err)
+		assert.Equal(t, info.SRS.EPSG, 3857)
+		for i, v := range info.WGS84Extent {
+			//40 x 20 meters at the equator is about 0.00036 x 0.00018 degrees
+			assert.Assert(t, math.Abs(v-[4]float64{0, 0, 0.000359, 0.000180}[i]) < 1e-6, "%v", info.WGS84Extent)
+		}
+	})
+}
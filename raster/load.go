@@ -2,8 +2,8 @@ package raster
 
 import (
 	"errors"
+	"fmt"
 	"github.com/airbusgeo/godal"
-	"github.com/canghel3/raster2image/utils"
 	"path/filepath"
 	"sync"
 )
@@ -32,11 +32,12 @@ func Load(path string, options ...LoadOption) (Driver, error) {
 		return nil, err
 	}
 
-	//TODO: run gdaladdo to create internal pyramids to improve efficiency
-
-	min, max, err := utils.MinMaxDs(ds)
-	if err != nil {
-		return nil, err
+	for _, band := range ds.Bands() {
+		switch band.Structure().DataType {
+		case godal.CInt16, godal.CInt32, godal.CFloat32, godal.CFloat64, godal.Unknown:
+			ds.Close()
+			return nil, fmt.Errorf("cannot load raster %s with %s data", path, band.Structure().DataType)
+		}
 	}
 
 	var driver Driver
@@ -45,8 +46,6 @@ func Load(path string, options ...LoadOption) (Driver, error) {
 		tifDriverData := TifDriverData{
 			Name:    path,
 			Dataset: ds,
-			Min:     min,
-			Max:     max,
 		}
 
 		driver = NewTifDriver(tifDriverData)
@@ -58,6 +57,39 @@ func Load(path string, options ...LoadOption) (Driver, error) {
 		option(driver)
 	}
 
+	err = driver.compileExpression()
+	if err != nil {
+		driver.Release()
+		return nil, err
+	}
+
+	//the color tables also pick the resampling of the overviews
+	err = driver.readPalettes()
+	if err != nil {
+		driver.Release()
+		return nil, err
+	}
+
+	err = driver.buildOverviews()
+	if err != nil {
+		driver.Release()
+		return nil, err
+	}
+
+	//the min and max depend on the NoData value, which can be overridden by the options
+	err = driver.computeRanges()
+	if err != nil {
+		driver.Release()
+		return nil, err
+	}
+
+	//the color ramp spreads over the min and max, unless the style sets its own
+	err = driver.expandColorRamp()
+	if err != nil {
+		driver.Release()
+		return nil, err
+	}
+
 	R.mx.Lock()
 	R.registry[filepath.Base(path)] = driver
 	R.mx.Unlock()
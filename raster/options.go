@@ -1,7 +1,9 @@
 package raster
 
 import (
+	"github.com/canghel3/raster2image/models"
 	"github.com/canghel3/raster2image/parser"
+	"github.com/canghel3/raster2image/tiles"
 	"path/filepath"
 )
 
@@ -18,3 +20,236 @@ func WithStyle(style string) func(driver Driver) {
 		}
 	}
 }
+
+// WithNoData overrides the NoData value of every band of the raster, for files whose metadata is wrong or missing.
+// NoData pixels are ignored when computing the min and max and are rendered transparent.
+func WithNoData(noData float64) func(driver Driver) {
+	return func(driver Driver) {
+		driver.setNoData(noData)
+	}
+}
+
+// WithExpression renders the band math expression, e.g. ndvi(b4, b3), instead of the bands of the raster, see package expr.
+// It overrides the raster-expression of the style. Load fails when the expression is invalid or reads bands the raster does not have.
+func WithExpression(expression string) func(driver Driver) {
+	return func(driver Driver) {
+		driver.setExpression(expression)
+	}
+}
+
+// DefaultSRS is the SRS of the rendered images and of the bbox when no other SRS is given.
+const DefaultSRS = "EPSG:3857"
+
+// WithOverviews builds overviews (pyramids) for the raster when it is loaded, so that rendering small scales does not
+// read the full resolution data. Overviews are stored inside the file when it is writable and in a .ovr sidecar file otherwise.
+// Levels that already exist are not built again.
+func WithOverviews(options ...OverviewOption) func(driver Driver) {
+	return func(driver Driver) {
+		var config overviewConfig
+		for _, option := range options {
+			option(&config)
+		}
+
+		driver.setOverviews(config)
+	}
+}
+
+type OverviewOption func(config *overviewConfig)
+
+type overviewConfig struct {
+	levels     []int
+	resampling Resampling
+	progress   func(complete float64)
+}
+
+// OverviewLevels sets the decimation factors of the overviews, e.g. 2, 4, 8, 16.
+// Defaults to powers of 2 until the overview fits in a 256x256 tile.
+func OverviewLevels(levels ...int) OverviewOption {
+	return func(config *overviewConfig) {
+		config.levels = levels
+	}
+}
+
+// OverviewResampling sets the resampling used to compute the overviews. Defaults to ResampleAverage,
+// or to ResampleMode for rasters with a paletted band, whose indexes must not be averaged.
+// ResampleMin, ResampleMax and ResampleMedian are not supported for overviews.
+func OverviewResampling(resampling Resampling) OverviewOption {
+	return func(config *overviewConfig) {
+		config.resampling = resampling
+	}
+}
+
+// OverviewProgress is called with the completed fraction, 0 before the overviews are built and again after each level is built,
+// up to 1 once they are all built.
+func OverviewProgress(progress func(complete float64)) OverviewOption {
+	return func(config *overviewConfig) {
+		config.progress = progress
+	}
+}
+
+type RenderOption func(options *renderOptions)
+
+type renderOptions struct {
+	channels   *models.Channels
+	srs        string
+	bboxSRS    string
+	tileSize   uint
+	scale      uint
+	tms        bool
+	resampling Resampling
+	//fail with ErrOutsideExtent instead of rendering a transparent image
+	outsideExtentError bool
+	stretch            *models.Stretch
+	mode               models.RenderMode
+	hillshade          *models.Hillshade
+	slope              *models.Slope
+	relief             *models.Relief
+}
+
+func newRenderOptions(options ...RenderOption) *renderOptions {
+	ro := &renderOptions{
+		srs:        DefaultSRS,
+		tileSize:   tiles.DefaultTileSize,
+		scale:      1,
+		resampling: ResampleNearest,
+	}
+	for _, option := range options {
+		option(ro)
+	}
+
+	if ro.bboxSRS == "" {
+		ro.bboxSRS = ro.srs
+	}
+
+	return ro
+}
+
+// WithSRS renders the image in the given SRS, in any format understood by GDAL (e.g. "EPSG:4326" or a WKT).
+// The bbox is expected in the same SRS, unless WithBBoxSRS is used. Defaults to DefaultSRS.
+func WithSRS(srs string) RenderOption {
+	return func(options *renderOptions) {
+		options.srs = srs
+	}
+}
+
+// WithBBoxSRS sets the SRS the bbox is expressed in, when it differs from the SRS of the rendered image.
+func WithBBoxSRS(srs string) RenderOption {
+	return func(options *renderOptions) {
+		options.bboxSRS = srs
+	}
+}
+
+// WithBand renders only the given band, as grayscale or through the style's color map.
+// Band indices start from 1. It overrides the raster-channels of the style.
+func WithBand(band int) RenderOption {
+	return func(options *renderOptions) {
+		options.channels = &models.Channels{Gray: band}
+	}
+}
+
+// WithChannels assigns the given bands to the red, green and blue components of the rendered image.
+// Band indices start from 1. It overrides the raster-channels of the style.
+func WithChannels(red, green, blue int) RenderOption {
+	return func(options *renderOptions) {
+		alpha := 0
+		if options.channels != nil && options.channels.Gray == 0 {
+			alpha = options.channels.Alpha
+		}
+
+		options.channels = &models.Channels{Red: red, Green: green, Blue: blue, Alpha: alpha}
+	}
+}
+
+// WithAlphaChannel uses the given band as the alpha component of the rendered image.
+// It should be used together with WithBand or WithChannels, otherwise the default red, green and blue bands (1, 2, 3) are used.
+func WithAlphaChannel(alpha int) RenderOption {
+	return func(options *renderOptions) {
+		if options.channels == nil {
+			options.channels = &models.Channels{Red: 1, Green: 2, Blue: 3}
+		}
+
+		options.channels.Alpha = alpha
+	}
+}
+
+// WithResampling sets the algorithm used to resample the raster to the rendered image. Defaults to ResampleNearest.
+// Categorical rasters are usually best rendered with ResampleNearest or ResampleMode and continuous ones with ResampleBilinear or ResampleCubic.
+func WithResampling(resampling Resampling) RenderOption {
+	return func(options *renderOptions) {
+		options.resampling = resampling
+	}
+}
+
+// WithTileSize sets the size in pixels of the tiles rendered by RenderTile. Defaults to 256.
+func WithTileSize(size uint) RenderOption {
+	return func(options *renderOptions) {
+		options.tileSize = size
+	}
+}
+
+// WithScale multiplies the size of the tiles rendered by RenderTile, e.g. 2 for retina displays. Defaults to 1.
+func WithScale(scale uint) RenderOption {
+	return func(options *renderOptions) {
+		options.scale = scale
+	}
+}
+
+// WithTMS makes RenderTile address tiles with the TMS scheme, where y grows from south to north, instead of XYZ.
+func WithTMS() RenderOption {
+	return func(options *renderOptions) {
+		options.tms = true
+	}
+}
+
+// WithOutsideExtentError makes renders of a bbox which does not overlap the raster fail with ErrOutsideExtent,
+// instead of returning a fully transparent image.
+func WithOutsideExtentError() RenderOption {
+	return func(options *renderOptions) {
+		options.outsideExtentError = true
+	}
+}
+
+// WithStretch sets how a single band without a color map is rendered as grayscale, see models.Stretch.WithDefaults for its zero fields.
+// It overrides the raster-stretch of the style.
+func WithStretch(stretch models.Stretch) RenderOption {
+	return func(options *renderOptions) {
+		stretch = stretch.WithDefaults()
+		options.stretch = &stretch
+	}
+}
+
+// WithHillshade renders a single band as an elevation model lit as given, see models.DefaultHillshade.
+// It overrides the raster-mode of the style.
+func WithHillshade(hillshade models.Hillshade) RenderOption {
+	return func(options *renderOptions) {
+		options.mode = models.ModeHillshade
+		options.hillshade = &hillshade
+	}
+}
+
+// WithRelief colors a single band as an elevation model through the style's color map and blends its hillshade,
+// lit as set by the style or WithHillshade, on top as given, its zero fields set as in models.DefaultRelief.
+// It overrides the raster-mode of the style.
+func WithRelief(relief models.Relief) RenderOption {
+	return func(options *renderOptions) {
+		options.mode = models.ModeRelief
+		options.relief = &relief
+	}
+}
+
+// WithSlope renders the steepness of a single band as an elevation model, measured as given.
+// It overrides the raster-mode of the style.
+func WithSlope(slope models.Slope) RenderOption {
+	return func(options *renderOptions) {
+		options.mode = models.ModeSlope
+		options.slope = &slope
+	}
+}
+
+// WithAspect renders the compass direction the slopes of a single band, as an elevation model, face.
+// It overrides the raster-mode of the style.
+func WithAspect() RenderOption {
+	return func(options *renderOptions) {
+		options.mode = models.ModeAspect
+	}
+}
@@ -0,0 +1,163 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/airbusgeo/godal"
+	"math"
+	"sort"
+)
+
+// overviewMinSize is the size under which no more overview levels are built by default.
+const overviewMinSize = 256
+
+// godalResampling returns the godal equivalent of the resampling for building overviews.
+func (r Resampling) godalResampling() (godal.ResamplingAlg, error) {
+	switch r {
+	case ResampleNearest:
+		return godal.Nearest, nil
+	case ResampleBilinear:
+		return godal.Bilinear, nil
+	case ResampleCubic:
+		return godal.Cubic, nil
+	case ResampleCubicSpline:
+		return godal.CubicSpline, nil
+	case ResampleLanczos:
+		return godal.Lanczos, nil
+	case ResampleAverage:
+		return godal.Average, nil
+	case ResampleMode:
+		return godal.Mode, nil
+	}
+
+	return 0, fmt.Errorf("resampling %q is not supported for overviews", r)
+}
+
+// defaultOverviewLevels returns powers of 2 until the overview of a sizeX x sizeY raster fits in overviewMinSize.
+func defaultOverviewLevels(sizeX, sizeY int) []int {
+	var levels []int
+	for level := 2; sizeX/(level/2) > overviewMinSize || sizeY/(level/2) > overviewMinSize; level *= 2 {
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+// defaultOverviewResampling averages the pixels of the overviews, but for rasters with a paletted band,
+// whose classes are kept by taking the most frequent one.
+func (td *TifDriver) defaultOverviewResampling() Resampling {
+	for _, palette := range td.palettes {
+		if palette != nil {
+			return ResampleMode
+		}
+	}
+
+	return ResampleAverage
+}
+
+// existingOverviewLevels returns the decimation factors of the overviews the band already has.
+func existingOverviewLevels(band godal.Band) map[int]bool {
+	sizeX := band.Structure().SizeX
+	levels := make(map[int]bool)
+	for _, overview := range band.Overviews() {
+		ovrSizeX := overview.Structure().SizeX
+		if ovrSizeX > 0 {
+			levels[int(math.Round(float64(sizeX)/float64(ovrSizeX)))] = true
+		}
+	}
+
+	return levels
+}
+
+func (td *TifDriver) setOverviews(config overviewConfig) {
+	td.overviews = &config
+}
+
+// buildOverviews builds the overview levels requested by WithOverviews that the dataset does not have yet, one at a time
+// so that the progress is reported after each of them.
+// The overviews go inside the file when it can be opened for writing, in which case the dataset is reopened to pick them up,
+// and in an external .ovr file otherwise.
+func (td *TifDriver) buildOverviews() error {
+	if td.overviews == nil {
+		return nil
+	}
+
+	resampling := td.overviews.resampling
+	if resampling == "" {
+		resampling = td.defaultOverviewResampling()
+	}
+
+	alg, err := resampling.godalResampling()
+	if err != nil {
+		return err
+	}
+
+	structure := td.dataset.Structure()
+	levels := td.overviews.levels
+	if len(levels) == 0 {
+		levels = defaultOverviewLevels(structure.SizeX, structure.SizeY)
+	}
+
+	existing := existingOverviewLevels(td.dataset.Bands()[0])
+	var missing []int
+	for _, level := range levels {
+		if level < 2 {
+			return fmt.Errorf("invalid overview level %d", level)
+		}
+		if !existing[level] {
+			missing = append(missing, level)
+		}
+	}
+
+	progress := td.overviews.progress
+	if progress == nil {
+		progress = func(float64) {}
+	}
+
+	if len(missing) == 0 {
+		progress(1)
+		return nil
+	}
+
+	td.lock.Lock()
+	defer td.lock.Unlock()
+
+	ds, err := godal.Open(td.name, godal.Update())
+	internal := err == nil
+	if !internal {
+		//read-only datasets write their overviews to a .ovr sidecar
+		ds = td.dataset
+	}
+
+	//godal has no progress callback, so the levels are built one by one, from the largest overview, to report the progress
+	sort.Ints(missing)
+	progress(0)
+	for i, level := range missing {
+		err = ds.BuildOverviews(godal.Levels(level), godal.Resampling(alg))
+		if err != nil {
+			if internal {
+				ds.Close()
+			}
+			return err
+		}
+		progress(float64(i+1) / float64(len(missing)))
+	}
+
+	if !internal {
+		return nil
+	}
+
+	err = ds.Close()
+	if err != nil {
+		return err
+	}
+
+	//the dataset was opened before the overviews existed, so it has to be opened again to use them
+	reopened, err := godal.Open(td.name)
+	if err != nil {
+		return err
+	}
+
+	err = td.dataset.Close()
+	td.dataset = reopened
+	return err
+}
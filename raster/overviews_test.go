@@ -0,0 +1,44 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestDefaultOverviewLevels(t *testing.T) {
+	assert.DeepEqual(t, defaultOverviewLevels(256, 256), []int(nil))
+	assert.DeepEqual(t, defaultOverviewLevels(257, 100), []int{2})
+	assert.DeepEqual(t, defaultOverviewLevels(40000, 40000), []int{2, 4, 8, 16, 32, 64, 128, 256})
+}
+
+func TestLoadOverviews(t *testing.T) {
+	const width, height = 64, 64
+	path := createTestRaster(t, "overviews.tif", godal.Byte, width, height, ramp(width*height, 0, 0.05))
+
+	var progress []float64
+	driver, err := Load(path, WithOverviews(OverviewLevels(4, 2, 8, 16), OverviewProgress(func(complete float64) {
+		progress = append(progress, complete)
+	})))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, progress, []float64{0, 0.25, 0.5, 0.75, 1})
+
+	levels := existingOverviewLevels(driver.(*TifDriver).dataset.Bands()[0])
+	assert.DeepEqual(t, levels, map[int]bool{2: true, 4: true, 8: true, 16: true})
+
+	t.Run("EXISTING LEVELS", func(t *testing.T) {
+		Release(path)
+		progress = nil
+		_, err := Load(path, WithOverviews(OverviewLevels(2, 4, 8, 16), OverviewProgress(func(complete float64) {
+			progress = append(progress, complete)
+		})))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, progress, []float64{1})
+	})
+
+	t.Run("UNSUPPORTED RESAMPLING", func(t *testing.T) {
+		Release(path)
+		_, err := Load(path, WithOverviews(OverviewResampling(ResampleMedian)))
+		assert.ErrorContains(t, err, "not supported for overviews")
+	})
+}
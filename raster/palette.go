@@ -0,0 +1,90 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"math"
+)
+
+// colorTable returns the color table of the band at index i, when the band is paletted with RGB or grayscale colors.
+func (td *TifDriver) colorTable(i int) (godal.ColorTable, bool) {
+	td.lock.RLock()
+	defer td.lock.RUnlock()
+
+	band := td.dataset.Bands()[i]
+	if band.ColorInterp() != godal.CIPalette {
+		return godal.ColorTable{}, false
+	}
+
+	table := band.ColorTable()
+	switch table.PaletteInterp {
+	case godal.RGBPalette, godal.GrayscalePalette:
+		return table, len(table.Entries) > 0
+	}
+	return godal.ColorTable{}, false
+}
+
+// readPalettes reads the color tables of the paletted bands as color maps, labelled with the categories of the bands,
+// so that renders do not read them again. Values that are not in a color table are transparent.
+func (td *TifDriver) readPalettes() error {
+	td.palettes = make([]*models.RasterStyle, len(td.dataset.Bands()))
+	for i := range td.palettes {
+		table, ok := td.colorTable(i)
+		if !ok {
+			continue
+		}
+
+		//the labels only matter to legends, a broken .aux.xml must not prevent rendering
+		labels := make(map[float64]string)
+		if categories, err := td.categories(i); err == nil {
+			for _, category := range categories {
+				labels[category.Value] = category.Name
+			}
+		}
+
+		style := &models.RasterStyle{ColorMapType: models.ColorMapValues}
+		for index := range table.Entries {
+			color, _ := paletteColor(table, float64(index))
+			style.ColorMap = append(style.ColorMap, models.ColorMapEntry{
+				Color:    color,
+				Quantity: float64(index),
+				Opacity:  1,
+				Label:    labels[float64(index)],
+			})
+		}
+		td.palettes[i] = style
+	}
+
+	return nil
+}
+
+// palette returns the color table of the band at index i as a color map, or nil when the band is not paletted.
+func (td *TifDriver) palette(i int) *models.RasterStyle {
+	if i < 0 || i >= len(td.palettes) {
+		return nil
+	}
+
+	return td.palettes[i]
+}
+
+// paletteColor returns the hex color of the value in the color table, false when the table has no entry for it.
+func paletteColor(table godal.ColorTable, value float64) (string, bool) {
+	if value < 0 || value >= float64(len(table.Entries)) || value != math.Trunc(value) {
+		return "", false
+	}
+
+	entry := table.Entries[int(value)]
+	if table.PaletteInterp == godal.GrayscalePalette {
+		return hexColor(uint8(entry[0]), uint8(entry[0]), uint8(entry[0]), 255), true
+	}
+	return hexColor(uint8(entry[0]), uint8(entry[1]), uint8(entry[2]), uint8(entry[3])), true
+}
+
+// hexColor returns the color as #RRGGBB, or #RRGGBBAA when it is not opaque.
+func hexColor(r, g, b, a uint8) string {
+	if a == 255 {
+		return fmt.Sprintf("#%02X%02X%02X", r, g, b)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", r, g, b, a)
+}
@@ -0,0 +1,143 @@
+package raster
+
+import (
+	"encoding/xml"
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image/color"
+	"os"
+	"testing"
+)
+
+const testAuxXML = `<PAMDataset>
+  <PAMRasterBand band="1">
+    <GDALRasterAttributeTable tableType="thematic">
+      <FieldDefn index="0"><Name>Value</Name><Type>0</Type><Usage>0</Usage></FieldDefn>
+      <FieldDefn index="1"><Name>Class</Name><Type>2</Type><Usage>2</Usage></FieldDefn>
+      <FieldDefn index="2"><Name>Red</Name><Type>0</Type><Usage>6</Usage></FieldDefn>
+      <FieldDefn index="3"><Name>Green</Name><Type>0</Type><Usage>7</Usage></FieldDefn>
+      <FieldDefn index="4"><Name>Blue</Name><Type>0</Type><Usage>8</Usage></FieldDefn>
+      <Row index="0"><F>1</F><F>Water</F><F>0</F><F>0</F><F>255</F></Row>
+      <Row index="1"><F>2</F><F></F><F>0</F><F>0</F><F>0</F></Row>
+      <Row index="2"><F>3</F><F>Forest</F><F>0</F><F>128</F><F>0</F></Row>
+    </GDALRasterAttributeTable>
+  </PAMRasterBand>
+  <PAMRasterBand band="2">
+    <CategoryNames>
+      <Category>Unclassified</Category>
+      <Category></Category>
+      <Category>Urban</Category>
+    </CategoryNames>
+  </PAMRasterBand>
+</PAMDataset>`
+
+func TestAttributeTableCategories(t *testing.T) {
+	var pam pamDataset
+	assert.NilError(t, xml.Unmarshal([]byte(testAuxXML), &pam))
+	assert.Equal(t, len(pam.Bands), 2)
+	assert.DeepEqual(t, pam.Bands[0].AttributeTable.categories(), []models.Category{
+		{Value: 1, Name: "Water", Color: "#0000FF"},
+		{Value: 3, Name: "Forest", Color: "#008000"},
+	})
+	assert.DeepEqual(t, pam.Bands[1].CategoryNames, []string{"Unclassified", "", "Urban"})
+
+	t.Run("BINNED", func(t *testing.T) {
+		min, size := 100.0, 50.0
+		rat := pamRasterAttributeTable{
+			Row0Min: &min,
+			BinSize: &size,
+			Fields:  []pamFieldDef{{Index: 0, Name: "Label", Type: fieldString}},
+			Rows:    []pamRow{{Index: 0, Values: []string{"Low"}}, {Index: 2, Values: []string{"High"}}},
+		}
+		assert.DeepEqual(t, rat.categories(), []models.Category{{Value: 100, Name: "Low"}, {Value: 200, Name: "High"}})
+	})
+
+	t.Run("NO NAMES", func(t *testing.T) {
+		rat := pamRasterAttributeTable{Fields: []pamFieldDef{{Index: 0, Name: "Count", Usage: 1}}, Rows: []pamRow{{Values: []string{"10"}}}}
+		assert.Assert(t, rat.categories() == nil)
+	})
+}
+
+func TestPaletteColor(t *testing.T) {
+	table := godal.ColorTable{PaletteInterp: godal.RGBPalette, Entries: [][4]int16{{255, 0, 0, 255}, {0, 128, 255, 0}}}
+	c, ok := paletteColor(table, 1)
+	assert.Assert(t, ok)
+	assert.Equal(t, c, "#0080FF00")
+
+	for _, value := range []float64{-1, 2, 0.5} {
+		_, ok := paletteColor(table, value)
+		assert.Assert(t, !ok, "%f", value)
+	}
+
+	gray := godal.ColorTable{PaletteInterp: godal.GrayscalePalette, Entries: [][4]int16{{64}}}
+	c, _ = paletteColor(gray, 0)
+	assert.Equal(t, c, "#404040")
+}
+
+func TestRenderPalette(t *testing.T) {
+	const width, height = 4, 1
+	path := createTestRaster(t, "landcover.tif", godal.Byte, width, height, []float64{0, 1, 2, 3})
+
+	ds, err := godal.Open(path, godal.Update())
+	assert.NilError(t, err)
+	band := ds.Bands()[0]
+	assert.NilError(t, band.SetColorInterp(godal.CIPalette))
+	assert.NilError(t, band.SetColorTable(godal.ColorTable{
+		PaletteInterp: godal.RGBPalette,
+		Entries:       [][4]int16{{0, 0, 0, 0}, {0, 0, 255, 255}, {255, 0, 0, 255}},
+	}))
+	assert.NilError(t, ds.Close())
+
+	assert.NilError(t, os.WriteFile(path+".aux.xml", []byte(`<PAMDataset>
+  <PAMRasterBand band="1">
+    <CategoryNames>
+      <Category></Category>
+      <Category>Water</Category>
+      <Category>Urban</Category>
+    </CategoryNames>
+  </PAMRasterBand>
+</PAMDataset>`), 0644))
+
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("RENDER", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 255, A: 255})
+		//not in the color table
+		assert.Equal(t, nrgba(img, 3, 0).A, uint8(0))
+	})
+
+	t.Run("RESAMPLING", func(t *testing.T) {
+		//bilinear would blend the indexes 1 and 2 into 1.5, which is not a class
+		img, err := driver.Render(testBBox(width, height), width*2, height, WithResampling(ResampleBilinear))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 0), color.NRGBA{B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 4, 0), color.NRGBA{R: 255, A: 255})
+	})
+
+	t.Run("STYLE", func(t *testing.T) {
+		//the color map of the style takes precedence over the color table
+		driver.(*TifDriver).setStyle(&models.RasterStyle{ColorMap: []models.ColorMapEntry{{Color: "#00FF00", Quantity: 3, Opacity: 1}}})
+		defer driver.(*TifDriver).setStyle(nil)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{G: 255, A: 255})
+	})
+
+	t.Run("INFO", func(t *testing.T) {
+		info, err := driver.Info()
+		assert.NilError(t, err)
+		assert.Equal(t, info.Bands[0].ColorInterpretation, "Palette")
+		assert.DeepEqual(t, info.Bands[0].Categories, []models.Category{
+			{Value: 1, Name: "Water", Color: "#0000FF"},
+			{Value: 2, Name: "Urban", Color: "#FF0000"},
+		})
+		assert.Equal(t, driver.(*TifDriver).palette(0).ColorMap[1].Label, "Water")
+	})
+}
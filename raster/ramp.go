@@ -0,0 +1,72 @@
+package raster
+
+import (
+	"github.com/canghel3/raster2image/models"
+)
+
+// expandColorRamp replaces the style's raster-color-ramp with the color map entries it spreads over the rendered values,
+// interpolated unless the style sets another raster-color-map-type.
+func (td *TifDriver) expandColorRamp() error {
+	if td.style == nil || td.style.ColorRamp.Name == "" {
+		return nil
+	}
+
+	min, max, err := td.rampRange()
+	if err != nil {
+		return err
+	}
+
+	entries, err := td.style.ColorRamp.ColorMap(min, max)
+	if err != nil {
+		return err
+	}
+
+	//the style may be shared with other drivers
+	style := *td.style
+	style.ColorMap = entries
+	if style.ColorMapType == "" {
+		style.ColorMapType = models.ColorMapRamp
+	}
+	td.style = &style
+	return nil
+}
+
+// rampRange returns the range of the values rendered through the style's color map, when the color ramp does not set it:
+// the range of slopes or aspects for those modes, the approximate min and max of the expression,
+// or the min and max of the gray band.
+func (td *TifDriver) rampRange() (min, max float64, err error) {
+	ramp := td.style.ColorRamp
+	if ramp.Min != nil && ramp.Max != nil {
+		return *ramp.Min, *ramp.Max, nil
+	}
+
+	switch td.style.Mode {
+	case models.ModeSlope:
+		if td.style.Slope.Unit == models.SlopePercent {
+			return 0, 100, nil
+		}
+		return 0, 90, nil
+	case models.ModeAspect:
+		return 0, 360, nil
+	}
+
+	if td.expression != nil {
+		statistics, err := td.expressionStatistics(Approximate(), WithPercentiles())
+		if err != nil {
+			return 0, 0, err
+		}
+		return statistics.Min, statistics.Max, nil
+	}
+
+	gray := 1
+	channels, err := models.ParseChannels(td.style.RasterChannels)
+	if err != nil {
+		return 0, 0, err
+	}
+	if channels != nil && channels.Gray > 0 {
+		gray = channels.Gray
+	}
+
+	min, max = td.bandRange(gray - 1)
+	return min, max, nil
+}
@@ -0,0 +1,57 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadColorRamp(t *testing.T) {
+	const width, height = 4, 1
+	path := createTestRaster(t, "ramp.tif", godal.Float32, width, height, []float64{100, 200, 300, 500})
+
+	load := func(t *testing.T, css string) *TifDriver {
+		style := filepath.Join(t.TempDir(), "ramp.css")
+		assert.NilError(t, os.WriteFile(style, []byte("raster {\n    "+css+"\n}\n"), 0644))
+
+		driver, err := Load(path, WithStyle(style))
+		assert.NilError(t, err)
+		t.Cleanup(func() { driver.Release() })
+		return driver.(*TifDriver)
+	}
+
+	t.Run("STATISTICS", func(t *testing.T) {
+		driver := load(t, "raster-color-ramp: greys;")
+		colorMap := driver.style.ColorMap
+		assert.Equal(t, driver.style.ColorMapType, models.ColorMapRamp)
+		assert.Equal(t, colorMap[0].Quantity, 100.0)
+		assert.Equal(t, colorMap[len(colorMap)-1].Quantity, 500.0)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 3, 0), color.NRGBA{A: 255})
+	})
+
+	t.Run("RANGE AND REVERSE", func(t *testing.T) {
+		driver := load(t, "raster-color-ramp: greys 0 200 reverse;")
+		colorMap := driver.style.ColorMap
+		assert.Equal(t, colorMap[0], models.ColorMapEntry{Color: "#000000", Quantity: 0, Opacity: 1})
+		assert.Equal(t, colorMap[len(colorMap)-1], models.ColorMapEntry{Color: "#FFFFFF", Quantity: 200, Opacity: 1})
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		//clamped above 200
+		assert.Equal(t, nrgba(img, 3, 0), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	})
+
+	t.Run("SLOPE", func(t *testing.T) {
+		driver := load(t, "raster-mode: slope;\n    raster-color-ramp: viridis;")
+		colorMap := driver.style.ColorMap
+		assert.Equal(t, colorMap[len(colorMap)-1].Quantity, 90.0)
+	})
+}
@@ -0,0 +1,303 @@
+package raster
+
+import (
+	"math"
+	"sort"
+)
+
+// Resampling is the algorithm used to compute an output pixel from the raster pixels it covers.
+// The values are the names gdalwarp uses for -r.
+type Resampling string
+
+const (
+	// ResampleNearest takes the closest pixel
+	ResampleNearest Resampling = "near"
+	// ResampleBilinear interpolates the 2x2 closest pixels
+	ResampleBilinear Resampling = "bilinear"
+	// ResampleCubic interpolates the 4x4 closest pixels with a cubic convolution
+	ResampleCubic Resampling = "cubic"
+	// ResampleCubicSpline interpolates the 4x4 closest pixels with a cubic B-spline, which smooths more than ResampleCubic
+	ResampleCubicSpline Resampling = "cubicspline"
+	// ResampleLanczos interpolates the 6x6 closest pixels with a Lanczos windowed sinc
+	ResampleLanczos Resampling = "lanczos"
+	// ResampleAverage averages the pixels covered by the output pixel
+	ResampleAverage Resampling = "average"
+	// ResampleMode takes the most frequent value of the pixels covered by the output pixel
+	ResampleMode Resampling = "mode"
+	// ResampleMin takes the lowest value of the pixels covered by the output pixel
+	ResampleMin Resampling = "min"
+	// ResampleMax takes the highest value of the pixels covered by the output pixel
+	ResampleMax Resampling = "max"
+	// ResampleMedian takes the median value of the pixels covered by the output pixel
+	ResampleMedian Resampling = "med"
+)
+
+func (r Resampling) valid() bool {
+	switch r {
+	case ResampleNearest, ResampleBilinear, ResampleCubic, ResampleCubicSpline, ResampleLanczos,
+		ResampleAverage, ResampleMode, ResampleMin, ResampleMax, ResampleMedian:
+		return true
+	}
+
+	return false
+}
+
+// window is a fractional range of pixel coordinates, pixel (i, j) spanning [i, i+1) x [j, j+1).
+type window struct {
+	x0, y0, x1, y1 float64
+}
+
+func (w window) width() float64 {
+	return w.x1 - w.x0
+}
+
+func (w window) height() float64 {
+	return w.y1 - w.y0
+}
+
+// resample resizes src, of srcWidth x srcHeight pixels, to dstWidth x dstHeight pixels.
+// Pixels are aligned on their centers, like GDAL does. NaN pixels are ignored, unless every pixel used for an output pixel is NaN.
+func resample(src []float64, srcWidth, srcHeight, dstWidth, dstHeight int, resampling Resampling) []float64 {
+	if dstWidth == srcWidth && dstHeight == srcHeight {
+		// No resampling needed, just return a copy
+		out := make([]float64, len(src))
+		copy(out, src)
+		return out
+	}
+
+	return resampleWindow(src, srcWidth, srcHeight, window{0, 0, float64(srcWidth), float64(srcHeight)}, dstWidth, dstHeight, resampling)
+}
+
+// resampleWindow is like resample, but only the window of src is resized to dstWidth x dstHeight pixels.
+// The window does not have to be aligned on pixel edges. Pixels outside src are the closest pixel of src.
+func resampleWindow(src []float64, srcWidth, srcHeight int, win window, dstWidth, dstHeight int, resampling Resampling) []float64 {
+	switch resampling {
+	case ResampleBilinear:
+		return kernelResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight, 1, triangle)
+	case ResampleCubic:
+		return kernelResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight, 2, cubic)
+	case ResampleCubicSpline:
+		return kernelResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight, 2, cubicSpline)
+	case ResampleLanczos:
+		return kernelResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight, 3, lanczos)
+	case ResampleAverage, ResampleMode, ResampleMin, ResampleMax, ResampleMedian:
+		return areaResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight, resampling)
+	}
+
+	return nearestResample(src, srcWidth, srcHeight, win, dstWidth, dstHeight)
+}
+
+func nearestResample(src []float64, srcWidth, srcHeight int, win window, dstWidth, dstHeight int) []float64 {
+	out := make([]float64, dstWidth*dstHeight)
+	// Compute ratios
+	xRatio := win.width() / float64(dstWidth)
+	yRatio := win.height() / float64(dstHeight)
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			// Map the center of the output pixel back to source coordinates
+			srcX := int(math.Floor(win.x0 + (float64(x)+0.5)*xRatio))
+			srcY := int(math.Floor(win.y0 + (float64(y)+0.5)*yRatio))
+
+			out[y*dstWidth+x] = src[clampIndex(srcY, srcHeight)*srcWidth+clampIndex(srcX, srcWidth)]
+		}
+	}
+
+	return out
+}
+
+// kernelResample resamples with a separable interpolation kernel of the given radius.
+// When downsampling, the kernel is stretched to cover every source pixel under the output pixel, like GDAL does.
+func kernelResample(src []float64, srcWidth, srcHeight int, win window, dstWidth, dstHeight int, radius float64, kernel func(float64) float64) []float64 {
+	xRatio := win.width() / float64(dstWidth)
+	yRatio := win.height() / float64(dstHeight)
+	xScale := math.Max(1, xRatio)
+	yScale := math.Max(1, yRatio)
+
+	out := make([]float64, dstWidth*dstHeight)
+	for y := 0; y < dstHeight; y++ {
+		// Map the center of the output pixel to fractional source coordinates
+		srcY := win.y0 + (float64(y)+0.5)*yRatio - 0.5
+		y0 := int(math.Ceil(srcY - radius*yScale))
+		y1 := int(math.Floor(srcY + radius*yScale))
+
+		for x := 0; x < dstWidth; x++ {
+			srcX := win.x0 + (float64(x)+0.5)*xRatio - 0.5
+			x0 := int(math.Ceil(srcX - radius*xScale))
+			x1 := int(math.Floor(srcX + radius*xScale))
+
+			var sum, weights float64
+			for sy := y0; sy <= y1; sy++ {
+				wy := kernel((float64(sy) - srcY) / yScale)
+				if wy == 0 {
+					continue
+				}
+
+				row := clampIndex(sy, srcHeight) * srcWidth
+				for sx := x0; sx <= x1; sx++ {
+					w := wy * kernel((float64(sx)-srcX)/xScale)
+					v := src[row+clampIndex(sx, srcWidth)]
+					if w == 0 || math.IsNaN(v) {
+						continue
+					}
+
+					sum += w * v
+					weights += w
+				}
+			}
+
+			if weights == 0 {
+				out[y*dstWidth+x] = math.NaN()
+				continue
+			}
+			out[y*dstWidth+x] = sum / weights
+		}
+	}
+
+	return out
+}
+
+// areaResample resamples by reducing the source pixels covered by each output pixel to a single value.
+// When upsampling, an output pixel covers a single source pixel.
+func areaResample(src []float64, srcWidth, srcHeight int, win window, dstWidth, dstHeight int, resampling Resampling) []float64 {
+	xRatio := win.width() / float64(dstWidth)
+	yRatio := win.height() / float64(dstHeight)
+
+	out := make([]float64, dstWidth*dstHeight)
+	values := make([]float64, 0, int(math.Ceil(xRatio+1)*math.Ceil(yRatio+1)))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := footprint(y, win.y0, yRatio, srcHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := footprint(x, win.x0, xRatio, srcWidth)
+
+			values = values[:0]
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					if v := src[sy*srcWidth+sx]; !math.IsNaN(v) {
+						values = append(values, v)
+					}
+				}
+			}
+
+			out[y*dstWidth+x] = reduce(values, resampling)
+		}
+	}
+
+	return out
+}
+
+// footprint returns the range [start, end) of source pixels covered by the output pixel i,
+// for output pixels of ratio source pixels starting at the source coordinate origin.
+func footprint(i int, origin, ratio float64, size int) (start, end int) {
+	start = clampIndex(int(math.Floor(origin+float64(i)*ratio)), size)
+	end = int(math.Ceil(origin + float64(i+1)*ratio))
+	if end > size {
+		end = size
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	return start, end
+}
+
+// reduce computes the average, mode, min, max or median of values. values is reordered.
+func reduce(values []float64, resampling Resampling) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	switch resampling {
+	case ResampleAverage:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	case ResampleMode:
+		counts := make(map[float64]int, len(values))
+		mode, best := values[0], 0
+		for _, v := range values {
+			counts[v]++
+			//ties are won by the lowest value, to be independent of the iteration order
+			if counts[v] > best || (counts[v] == best && v < mode) {
+				mode, best = v, counts[v]
+			}
+		}
+		return mode
+	case ResampleMin:
+		min := values[0]
+		for _, v := range values {
+			min = math.Min(min, v)
+		}
+		return min
+	case ResampleMax:
+		max := values[0]
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return max
+	case ResampleMedian:
+		sort.Float64s(values)
+		return values[len(values)/2]
+	}
+
+	return values[0]
+}
+
+func clampIndex(i, size int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= size {
+		return size - 1
+	}
+	return i
+}
+
+func triangle(x float64) float64 {
+	x = math.Abs(x)
+	if x < 1 {
+		return 1 - x
+	}
+	return 0
+}
+
+// cubic is the cubic convolution kernel with a = -0.5 (Catmull-Rom), as used by GDAL.
+func cubic(x float64) float64 {
+	const a = -0.5
+	x = math.Abs(x)
+	switch {
+	case x <= 1:
+		return (a+2)*x*x*x - (a+3)*x*x + 1
+	case x < 2:
+		return a*x*x*x - 5*a*x*x + 8*a*x - 4*a
+	}
+	return 0
+}
+
+// cubicSpline is the cubic B-spline kernel.
+func cubicSpline(x float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x <= 1:
+		return (4 - 6*x*x + 3*x*x*x) / 6
+	case x < 2:
+		return (2 - x) * (2 - x) * (2 - x) / 6
+	}
+	return 0
+}
+
+// lanczos is the Lanczos kernel with a = 3.
+func lanczos(x float64) float64 {
+	const a = 3
+	x = math.Abs(x)
+	if x == 0 {
+		return 1
+	}
+	if x >= a {
+		return 0
+	}
+	px := math.Pi * x
+	return a * math.Sin(px) * math.Sin(px/a) / (px * px)
+}
@@ -0,0 +1,75 @@
+package raster
+
+import (
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	// 4x2 source
+	src := []float64{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+	}
+
+	t.Run("SAME SIZE", func(t *testing.T) {
+		for _, resampling := range []Resampling{ResampleNearest, ResampleBilinear, ResampleLanczos, ResampleMode} {
+			assert.DeepEqual(t, resample(src, 4, 2, 4, 2, resampling), src)
+		}
+	})
+
+	t.Run("NEAREST", func(t *testing.T) {
+		assert.DeepEqual(t, resample(src, 4, 2, 2, 1, ResampleNearest), []float64{6, 8})
+		assert.DeepEqual(t, resample(src, 4, 2, 8, 2, ResampleNearest), []float64{
+			1, 1, 2, 2, 3, 3, 4, 4,
+			5, 5, 6, 6, 7, 7, 8, 8,
+		})
+	})
+
+	t.Run("AREA", func(t *testing.T) {
+		assert.DeepEqual(t, resample(src, 4, 2, 2, 1, ResampleAverage), []float64{3.5, 5.5})
+		assert.DeepEqual(t, resample(src, 4, 2, 2, 1, ResampleMin), []float64{1, 3})
+		assert.DeepEqual(t, resample(src, 4, 2, 2, 1, ResampleMax), []float64{6, 8})
+		assert.DeepEqual(t, resample(src, 4, 2, 2, 1, ResampleMedian), []float64{5, 7})
+
+		categories := []float64{
+			1, 1, 2, 3,
+			1, 2, 3, 3,
+		}
+		assert.DeepEqual(t, resample(categories, 4, 2, 2, 1, ResampleMode), []float64{1, 3})
+	})
+
+	t.Run("BILINEAR", func(t *testing.T) {
+		out := resample([]float64{0, 10}, 2, 1, 4, 1, ResampleBilinear)
+		assert.DeepEqual(t, out, []float64{0, 2.5, 7.5, 10})
+
+		//downsampling averages the covered pixels
+		out = resample(src, 4, 2, 2, 1, ResampleBilinear)
+		assert.Check(t, out[0] > 1 && out[0] < 8)
+	})
+
+	t.Run("KERNELS PRESERVE CONSTANTS", func(t *testing.T) {
+		constant := []float64{7, 7, 7, 7, 7, 7, 7, 7, 7}
+		for _, resampling := range []Resampling{ResampleBilinear, ResampleCubic, ResampleCubicSpline, ResampleLanczos} {
+			for _, v := range resample(constant, 3, 3, 7, 5, resampling) {
+				assert.Check(t, math.Abs(v-7) < 1e-9, "%s: %f", resampling, v)
+			}
+		}
+	})
+
+	t.Run("NAN", func(t *testing.T) {
+		withNaN := []float64{math.NaN(), 2, 4, math.NaN()}
+		assert.DeepEqual(t, resample(withNaN, 2, 2, 1, 1, ResampleAverage), []float64{3})
+
+		out := resample([]float64{math.NaN()}, 1, 1, 2, 2, ResampleBilinear)
+		for _, v := range out {
+			assert.Check(t, math.IsNaN(v))
+		}
+	})
+
+	t.Run("VALID", func(t *testing.T) {
+		assert.Check(t, ResampleMedian.valid())
+		assert.Check(t, !Resampling("gauss").valid())
+	})
+}
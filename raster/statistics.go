@@ -0,0 +1,171 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/stats"
+	"math"
+)
+
+const (
+	// approximateSamples is about the number of pixels read to compute approximate statistics
+	approximateSamples = 1 << 20
+	// windowPixels is about the number of pixels of a band read at once to compute exact statistics
+	windowPixels = 1 << 20
+)
+
+type StatisticsOption func(options *statisticsOptions)
+
+type statisticsOptions struct {
+	approximate bool
+	bins        int
+	percentiles []float64
+}
+
+func newStatisticsOptions(options ...StatisticsOption) *statisticsOptions {
+	so := &statisticsOptions{
+		bins:        stats.DefaultBins,
+		percentiles: stats.DefaultPercentiles,
+	}
+	for _, option := range options {
+		option(so)
+	}
+
+	return so
+}
+
+// Approximate computes the statistics from about a million pixels spread over the whole band instead of every pixel.
+// GDAL reads them from the overviews, when the raster has some.
+// Exact statistics read every pixel a few times, by windows of blocks, so they are slow on large rasters but do not hold them in memory.
+func Approximate() StatisticsOption {
+	return func(options *statisticsOptions) {
+		options.approximate = true
+	}
+}
+
+// WithBins sets the number of bins of the histogram. Defaults to stats.DefaultBins.
+func WithBins(bins int) StatisticsOption {
+	return func(options *statisticsOptions) {
+		options.bins = bins
+	}
+}
+
+// WithPercentiles sets the percentiles to compute, from 0 to 100. Defaults to stats.DefaultPercentiles.
+func WithPercentiles(ranks ...float64) StatisticsOption {
+	return func(options *statisticsOptions) {
+		options.percentiles = ranks
+	}
+}
+
+// Statistics computes the statistics of the band (starting from 1), ignoring NoData, NaN and infinite values.
+// The statistics are cached, so they are computed once for every band and set of options.
+// The returned statistics are shared and must not be modified.
+func (td *TifDriver) Statistics(band int, options ...StatisticsOption) (*stats.Statistics, error) {
+	bandCount := len(td.dataset.Bands())
+	if band < 1 || band > bandCount {
+		return nil, fmt.Errorf("band %d does not exist in raster %s with %d Bands", band, td.name, bandCount)
+	}
+
+	so := newStatisticsOptions(options...)
+	key := fmt.Sprintf("%d %t %d %v", band, so.approximate, so.bins, so.percentiles)
+	if cached, ok := td.statistics.Load(key); ok {
+		return cached.(*stats.Statistics), nil
+	}
+
+	statsOptions := []stats.Option{stats.Bins(so.bins), stats.Percentiles(so.percentiles...)}
+	if noData, ok := td.bandNoData(band - 1); ok {
+		statsOptions = append(statsOptions, stats.NoData(noData))
+	}
+
+	var statistics stats.Statistics
+	if so.approximate && td.sampled() {
+		data, err := td.readSample(band - 1)
+		if err != nil {
+			return nil, err
+		}
+		statistics = stats.Compute(data, statsOptions...)
+	} else {
+		var err error
+		statistics, err = stats.ComputeSource(func(yield func(block []float64) error) error {
+			return td.readBlocks([]int{band - 1}, func(data [][]float64) error {
+				return yield(data[0])
+			})
+		}, statsOptions...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cached, _ := td.statistics.LoadOrStore(key, &statistics)
+	return cached.(*stats.Statistics), nil
+}
+
+// sampled reports whether the raster has more than approximateSamples pixels, so that approximate statistics are computed from a sample.
+func (td *TifDriver) sampled() bool {
+	structure := td.dataset.Structure()
+	return structure.SizeX*structure.SizeY > approximateSamples
+}
+
+// readSample reads about approximateSamples pixels evenly spread over the band at index i.
+// GDAL reads them from the overviews, when the raster has some.
+func (td *TifDriver) readSample(i int) ([]float64, error) {
+	td.lock.RLock()
+	defer td.lock.RUnlock()
+
+	structure := td.dataset.Structure()
+	scale := math.Sqrt(float64(approximateSamples) / float64(structure.SizeX*structure.SizeY))
+	width := int(math.Max(1, math.Round(float64(structure.SizeX)*scale)))
+	height := int(math.Max(1, math.Round(float64(structure.SizeY)*scale)))
+
+	data := make([]float64, width*height)
+	err := td.dataset.Bands()[i].Read(0, 0, data, width, height, godal.Window(structure.SizeX, structure.SizeY))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readBlocks reads the bands at indexes window by window, so that whole bands are never held in memory.
+// yield receives the data of every band, in the order of indexes, for each window. The data is reused by the next window.
+func (td *TifDriver) readBlocks(indexes []int, yield func(data [][]float64) error) error {
+	td.lock.RLock()
+	defer td.lock.RUnlock()
+
+	bands := td.dataset.Bands()
+	structure := bands[indexes[0]].Structure()
+	windowWidth, windowHeight := blockWindow(structure.SizeX, structure.SizeY, structure.BlockSizeX, structure.BlockSizeY)
+
+	buffers := make([][]float64, len(indexes))
+	for i := range buffers {
+		buffers[i] = make([]float64, windowWidth*windowHeight)
+	}
+
+	data := make([][]float64, len(indexes))
+	for y := 0; y < structure.SizeY; y += windowHeight {
+		for x := 0; x < structure.SizeX; x += windowWidth {
+			width, height := min(windowWidth, structure.SizeX-x), min(windowHeight, structure.SizeY-y)
+			for i, index := range indexes {
+				data[i] = buffers[i][:width*height]
+				if err := bands[index].Read(x, y, data[i], width, height); err != nil {
+					return err
+				}
+			}
+
+			if err := yield(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// blockWindow returns the size of the windows a band of width by height pixels is read by: whole blocks of blockWidth by blockHeight pixels,
+// about windowPixels of them, full rows of blocks when they are small enough.
+func blockWindow(width, height, blockWidth, blockHeight int) (int, int) {
+	blockWidth, blockHeight = max(1, blockWidth), max(1, blockHeight)
+	windowWidth := min(width, blockWidth*max(1, windowPixels/(blockWidth*blockHeight)))
+	windowHeight := min(height, blockHeight*max(1, windowPixels/(windowWidth*blockHeight)))
+	return windowWidth, windowHeight
+}
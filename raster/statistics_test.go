@@ -0,0 +1,69 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"gotest.tools/v3/assert"
+	"testing"
+)
+
+func TestStatistics(t *testing.T) {
+	const width, height = 4, 4
+	data := ramp(width*height, 1, 1)
+	data[0] = -9999
+	path := createTestRaster(t, "statistics.tif", godal.Float32, width, height, data, ramp(width*height, 0, 2))
+	driver, err := Load(path, WithNoData(-9999))
+	assert.NilError(t, err)
+
+	statistics, err := driver.Statistics(1, WithBins(3), WithPercentiles(50))
+	assert.NilError(t, err)
+	assert.Equal(t, statistics.Count, 15)
+	assert.Equal(t, statistics.Min, 2.0)
+	assert.Equal(t, statistics.Max, 16.0)
+	assert.Equal(t, statistics.Mean, 9.0)
+	assert.Equal(t, statistics.Percentile(50), 9.0)
+	assert.DeepEqual(t, statistics.Histogram.Counts, []int{5, 5, 5})
+
+	t.Run("CACHED", func(t *testing.T) {
+		cached, err := driver.Statistics(1, WithBins(3), WithPercentiles(50))
+		assert.NilError(t, err)
+		assert.Equal(t, cached, statistics)
+
+		other, err := driver.Statistics(2)
+		assert.NilError(t, err)
+		assert.Equal(t, other.Max, 30.0)
+	})
+
+	t.Run("APPROXIMATE", func(t *testing.T) {
+		//small rasters are read whole anyway
+		approximate, err := driver.Statistics(1, Approximate(), WithBins(3), WithPercentiles(50))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, approximate, statistics)
+	})
+
+	t.Run("INVALID BAND", func(t *testing.T) {
+		_, err := driver.Statistics(3)
+		assert.ErrorContains(t, err, "band 3 does not exist")
+	})
+}
+
+func TestBlockWindow(t *testing.T) {
+	//rows of strips
+	width, height := blockWindow(40000, 40000, 40000, 1)
+	assert.Equal(t, width, 40000)
+	assert.Equal(t, height, windowPixels/40000)
+
+	//rows of tiles
+	width, height = blockWindow(40000, 40000, 256, 256)
+	assert.Equal(t, width*height, windowPixels)
+	assert.Equal(t, width%256+height%256, 0)
+
+	//blocks larger than a window are read whole
+	width, height = blockWindow(8000, 8000, 2048, 2048)
+	assert.Equal(t, width, 2048)
+	assert.Equal(t, height, 2048)
+
+	//small rasters are read at once
+	width, height = blockWindow(100, 50, 100, 8)
+	assert.Equal(t, width, 100)
+	assert.Equal(t, height, 50)
+}
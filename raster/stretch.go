@@ -0,0 +1,90 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/canghel3/raster2image/models"
+	"github.com/canghel3/raster2image/render"
+	"github.com/canghel3/raster2image/stats"
+)
+
+// equalizeBins is the number of bins of the histogram used by models.StretchEqualize
+const equalizeBins = 1024
+
+// stretch returns how the band at index i, or the expression when i is expressionBand, is rendered as grayscale,
+// following the render options or the style.
+// The percentiles, mean, standard deviation and histogram the stretches need are approximate statistics of the whole band,
+// so that a stretch looks the same on every tile.
+func (td *TifDriver) stretch(i int, ro *renderOptions) (render.Stretch, error) {
+	var s models.Stretch
+	if ro.stretch != nil {
+		s = *ro.stretch
+	} else if td.style != nil {
+		s = td.style.Stretch.WithDefaults()
+	}
+
+	var stretch render.Stretch
+	switch s.Type {
+	case "", models.StretchMinMax:
+		if i != expressionBand {
+			stretch = render.LinearStretch(td.bandRange(i))
+			break
+		}
+
+		statistics, err := td.stretchStatistics(i, Approximate(), WithPercentiles())
+		if err != nil {
+			return nil, err
+		}
+		stretch = render.LinearStretch(statistics.Min, statistics.Max)
+	case models.StretchPercentile:
+		if s.Low < 0 || s.Low >= s.High || s.High > 100 {
+			return nil, fmt.Errorf("invalid stretch percentiles %g and %g", s.Low, s.High)
+		}
+		statistics, err := td.stretchStatistics(i, Approximate(), WithPercentiles(s.Low, s.High))
+		if err != nil {
+			return nil, err
+		}
+		stretch = render.LinearStretch(statistics.Percentile(s.Low), statistics.Percentile(s.High))
+	case models.StretchStdDev:
+		if s.StdDevs <= 0 {
+			return nil, fmt.Errorf("invalid stretch standard deviations %g", s.StdDevs)
+		}
+		statistics, err := td.stretchStatistics(i, Approximate(), WithPercentiles())
+		if err != nil {
+			return nil, err
+		}
+		stretch = render.LinearStretch(statistics.Mean-s.StdDevs*statistics.StdDev, statistics.Mean+s.StdDevs*statistics.StdDev)
+	case models.StretchEqualize:
+		statistics, err := td.stretchStatistics(i, Approximate(), WithBins(equalizeBins))
+		if err != nil {
+			return nil, err
+		}
+		stretch = render.EqualizeStretch(statistics.Histogram)
+	default:
+		return nil, fmt.Errorf("unknown stretch %q", s.Type)
+	}
+
+	switch s.Curve {
+	case "", models.CurveLinear:
+		return stretch, nil
+	case models.CurveGamma:
+		if s.Gamma <= 0 {
+			return nil, fmt.Errorf("invalid gamma %g", s.Gamma)
+		}
+		return stretch.Gamma(s.Gamma), nil
+	case models.CurveLog:
+		return stretch.Log(), nil
+	case models.CurveSqrt:
+		return stretch.Sqrt(), nil
+	}
+
+	return nil, fmt.Errorf("unknown stretch curve %q", s.Curve)
+}
+
+// stretchStatistics returns the statistics of the band at index i, or of the expression when i is expressionBand.
+func (td *TifDriver) stretchStatistics(i int, options ...StatisticsOption) (*stats.Statistics, error) {
+	if i == expressionBand {
+		return td.expressionStatistics(options...)
+	}
+
+	return td.Statistics(i+1, options...)
+}
@@ -0,0 +1,264 @@
+package raster
+
+import (
+	"fmt"
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"github.com/canghel3/raster2image/render"
+	"github.com/canghel3/raster2image/tiles"
+	"image"
+	"math"
+)
+
+// metersPerDegree is the length of a degree of latitude, or of longitude at the equator
+const metersPerDegree = tiles.EarthRadius * math.Pi / 180
+
+// mode returns what is rendered from a single band, following the render options or the style.
+func (td *TifDriver) mode(ro *renderOptions) models.RenderMode {
+	if ro.mode != "" {
+		return ro.mode
+	}
+	if td.style != nil && td.style.Mode != "" {
+		return td.style.Mode
+	}
+
+	return models.ModeDefault
+}
+
+// hillshade returns how the band is lit, following the render options or the style, their zero fields set as in
+// models.DefaultHillshade.
+func (td *TifDriver) hillshade(ro *renderOptions) models.Hillshade {
+	if ro.hillshade != nil {
+		return ro.hillshade.WithDefaults()
+	}
+	if td.style != nil {
+		return td.style.Hillshade.WithDefaults()
+	}
+
+	return models.DefaultHillshade()
+}
+
+// renderHillshade renders the gray band of channels as an elevation model lit by the sun.
+// NoData pixels and pixels outside the raster are transparent.
+func (td *TifDriver) renderHillshade(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	hillshade := td.hillshade(ro)
+	elevation, coverage, err := td.elevation(bbox, width, height, channels.Gray, ro)
+	if err != nil {
+		return nil, err
+	}
+	elevation.ZFactor, elevation.Scale = hillshade.ZFactor, hillshade.Scale
+
+	var drawer render.Drawer = hillshadeDrawer(elevation, hillshade)
+	if mask, ok := validityMask(coverage, crop(elevation.Data, width, height)); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
+	}
+
+	return drawer.Draw()
+}
+
+// hillshadeDrawer lights the elevation as set by hillshade.
+func hillshadeDrawer(elevation render.Elevation, hillshade models.Hillshade) *render.HillshadeDrawer {
+	options := []render.HillshadeOption{render.Azimuth(hillshade.Azimuth), render.Altitude(hillshade.Altitude)}
+	if hillshade.Multidirectional {
+		options = append(options, render.Multidirectional())
+	}
+
+	return render.NewHillshadeDrawer(elevation, options...)
+}
+
+// relief returns how the hillshade is blended with the color map, following the render options or the style, their zero fields
+// set as in models.DefaultRelief.
+func (td *TifDriver) relief(ro *renderOptions) models.Relief {
+	if ro.relief != nil {
+		return ro.relief.WithDefaults()
+	}
+	if td.style != nil {
+		return td.style.Relief.WithDefaults()
+	}
+
+	return models.DefaultRelief()
+}
+
+// renderRelief colors the gray band of channels as an elevation model through the style's color map and blends its
+// hillshade on top. NoData pixels, pixels outside the raster and elevations the color map does not paint are transparent.
+func (td *TifDriver) renderRelief(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	if td.style == nil || len(td.style.ColorMap) == 0 {
+		return nil, fmt.Errorf("cannot render raster %s in %s mode without a color map", td.name, models.ModeRelief)
+	}
+
+	hillshade, relief := td.hillshade(ro), td.relief(ro)
+	elevation, coverage, err := td.elevation(bbox, width, height, channels.Gray, ro)
+	if err != nil {
+		return nil, err
+	}
+	elevation.ZFactor, elevation.Scale = hillshade.ZFactor, hillshade.Scale
+
+	var drawer render.Drawer = render.NewReliefDrawer(hillshadeDrawer(elevation, hillshade), *td.style,
+		render.Blend(relief.Blend), render.Strength(relief.Strength))
+	if mask, ok := validityMask(coverage, crop(elevation.Data, width, height)); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
+	}
+
+	return drawer.Draw()
+}
+
+// slope returns how the steepness of the band is measured, following the render options or the style.
+func (td *TifDriver) slope(ro *renderOptions) models.Slope {
+	if ro.slope != nil {
+		return *ro.slope
+	}
+	if td.style != nil {
+		return td.style.Slope
+	}
+
+	return models.Slope{}
+}
+
+// renderSlope renders the steepness of the gray band of channels as an elevation model, through the style's color map
+// if there is one. NoData pixels and pixels outside the raster are transparent.
+func (td *TifDriver) renderSlope(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	slope := td.slope(ro)
+	elevation, coverage, err := td.elevation(bbox, width, height, channels.Gray, ro)
+	if err != nil {
+		return nil, err
+	}
+	elevation.ZFactor, elevation.Scale = slope.ZFactor, slope.Scale
+
+	options := td.terrainOptions()
+	if slope.Unit == models.SlopePercent {
+		options = append(options, render.Percent())
+	}
+
+	drawer := render.NewSlopeDrawer(elevation, options...)
+	return terrainImage(drawer, drawer.Slope(), coverage, width, height)
+}
+
+// renderAspect renders the direction the slopes of the gray band of channels face, as an elevation model, through the
+// style's color map if there is one. Flat areas, NoData pixels and pixels outside the raster are transparent.
+func (td *TifDriver) renderAspect(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	elevation, coverage, err := td.elevation(bbox, width, height, channels.Gray, ro)
+	if err != nil {
+		return nil, err
+	}
+
+	drawer := render.NewAspectDrawer(elevation, td.terrainOptions()...)
+	return terrainImage(drawer, drawer.Aspect(), coverage, width, height)
+}
+
+// terrainOptions colors slopes and aspects through the style's color map, if there is one.
+func (td *TifDriver) terrainOptions() []render.TerrainOption {
+	if td.style != nil && len(td.style.ColorMap) > 0 {
+		return []render.TerrainOption{render.TerrainStyle(*td.style)}
+	}
+
+	return nil
+}
+
+// terrainImage draws the terrain values computed by drawer, with the pixels that are not covered or have no value transparent.
+func terrainImage(drawer render.Drawer, values, coverage []float64, width, height uint) (image.Image, error) {
+	if mask, ok := validityMask(coverage, values); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
+	}
+
+	return drawer.Draw()
+}
+
+// elevation reads the band (starting from 1) over the bbox with a one pixel border around it, see render.Elevation,
+// and returns it with the coverage of the bbox, without the border.
+// The pixel sizes are in ground meters, see cellSize.
+func (td *TifDriver) elevation(bbox [4]float64, width, height uint, band int, ro *renderOptions) (render.Elevation, []float64, error) {
+	//the border is one output pixel wide
+	borderX := (bbox[2] - bbox[0]) / float64(width)
+	borderY := (bbox[3] - bbox[1]) / float64(height)
+	buffered := [4]float64{bbox[0] - borderX, bbox[1] - borderY, bbox[2] + borderX, bbox[3] + borderY}
+
+	data, coverage, err := td.fetch(buffered, width+2, height+2, ro, band)
+	if err != nil {
+		return render.Elevation{}, nil, err
+	}
+
+	//uncovered pixels hold whatever the warp left in them
+	elevations := data[0]
+	for i, c := range coverage {
+		if c == 0 {
+			elevations[i] = math.NaN()
+		}
+	}
+
+	cellSizeX, cellSizeY, err := td.cellSize(bbox, width, height, ro)
+	if err != nil {
+		return render.Elevation{}, nil, err
+	}
+
+	return render.Elevation{
+		Data:      elevations,
+		Width:     int(width),
+		Height:    int(height),
+		CellSizeX: cellSizeX,
+		CellSizeY: cellSizeY,
+	}, crop(coverage, width, height), nil
+}
+
+// crop removes the one pixel border of data, which holds (width+2) x (height+2) values.
+func crop(data []float64, width, height uint) []float64 {
+	inner := make([]float64, width*height)
+	for y := 0; y < int(height); y++ {
+		copy(inner[y*int(width):(y+1)*int(width)], data[(y+1)*int(width+2)+1:])
+	}
+
+	return inner
+}
+
+// cellSize returns the ground size of an output pixel in meters, measured at the center of the bbox once reprojected to WGS84,
+// whatever the units and the distortion of the target SRS, e.g. degrees, or Web Mercator meters which are 1/cos(latitude) ground meters.
+func (td *TifDriver) cellSize(bbox [4]float64, width, height uint, ro *renderOptions) (x, y float64, err error) {
+	target, err := godal.NewSpatialRef(ro.srs)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer target.Close()
+
+	if ro.bboxSRS != ro.srs {
+		source, err := godal.NewSpatialRef(ro.bboxSRS)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer source.Close()
+
+		bbox, err = reprojectBounds(bbox, source, target)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	x = (bbox[2] - bbox[0]) / float64(width)
+	y = (bbox[3] - bbox[1]) / float64(height)
+
+	geographic, err := godal.NewSpatialRef(wgs84)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer geographic.Close()
+
+	transform, err := godal.NewTransform(target, geographic)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer transform.Close()
+
+	//the edges of the center pixel
+	centerX, centerY := (bbox[0]+bbox[2])/2, (bbox[1]+bbox[3])/2
+	lon := []float64{centerX - x/2, centerX + x/2, centerX, centerX}
+	lat := []float64{centerY, centerY, centerY - y/2, centerY + y/2}
+	if err = transform.TransformEx(lon, lat, nil, nil); err != nil {
+		return 0, 0, err
+	}
+
+	return groundDistance(lon[0], lat[0], lon[1], lat[1]), groundDistance(lon[2], lat[2], lon[3], lat[3]), nil
+}
+
+// groundDistance returns the distance in meters between two close points given in degrees.
+func groundDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	latitude := (lat1 + lat2) / 2 * math.Pi / 180
+	return math.Hypot((lon2-lon1)*metersPerDegree*math.Cos(latitude), (lat2-lat1)*metersPerDegree)
+}
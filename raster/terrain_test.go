@@ -0,0 +1,180 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"github.com/canghel3/raster2image/tiles"
+	"gotest.tools/v3/assert"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// slope returns the elevations of a width x height raster rising towards the east by rise per pixel.
+func slope(width, height int, rise float64) []float64 {
+	data := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			data[y*width+x] = float64(x) * rise
+		}
+	}
+	return data
+}
+
+func gray(y uint8) color.NRGBA {
+	return color.NRGBA{R: y, G: y, B: y, A: 255}
+}
+
+func TestRenderHillshade(t *testing.T) {
+	const width, height = 8, 8
+
+	//45 degrees slopes, the pixels being 10 meters wide
+	path := createTestRaster(t, "dem.tif", godal.Float32, width, height, slope(width, height, testPixelSize))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	expected := uint8(math.Round((math.Sqrt(0.5) + 0.5) / math.Sqrt(2) * 255))
+
+	t.Run("RENDER OPTION", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithHillshade(models.DefaultHillshade()))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(expected))
+	})
+
+	t.Run("ZERO VALUE", func(t *testing.T) {
+		//the unset azimuth and altitude are the default ones, not a light from the horizon
+		img, err := driver.Render(testBBox(width, height), width, height, WithHillshade(models.Hillshade{}))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(expected))
+	})
+
+	t.Run("SEAMLESS", func(t *testing.T) {
+		//the right half of the raster, the first column has neighbours read from the left half
+		bbox := testBBox(width, height)
+		bbox[0] = width / 2 * testPixelSize
+		img, err := driver.Render(bbox, width/2, height, WithHillshade(models.DefaultHillshade()))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 3), gray(expected))
+	})
+
+	t.Run("STYLE", func(t *testing.T) {
+		style := &models.RasterStyle{Mode: models.ModeHillshade, Hillshade: models.DefaultHillshade()}
+		style.Hillshade.ZFactor = 2
+		driver.(*TifDriver).setStyle(style)
+		defer driver.(*TifDriver).setStyle(nil)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Assert(t, nrgba(img, 3, 3).R > expected)
+	})
+
+	t.Run("OUTSIDE", func(t *testing.T) {
+		bbox := testBBox(width, height)
+		bbox[2] += width * testPixelSize
+		img, err := driver.Render(bbox, width*2, height, WithHillshade(models.DefaultHillshade()))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, width+1, 3).A, uint8(0))
+	})
+}
+
+func TestRenderRelief(t *testing.T) {
+	const width, height = 8, 8
+
+	path := createTestRaster(t, "dem.tif", godal.Float32, width, height, slope(width, height, testPixelSize))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	_, err = driver.Render(testBBox(width, height), width, height, WithRelief(models.DefaultRelief()))
+	assert.ErrorContains(t, err, "without a color map")
+
+	style := &models.RasterStyle{
+		Mode:      models.ModeRelief,
+		Hillshade: models.DefaultHillshade(),
+		Relief:    models.DefaultRelief(),
+		ColorMap:  []models.ColorMapEntry{{Color: "#FFFFFF", Quantity: 1000, Opacity: 1}},
+	}
+	driver.(*TifDriver).setStyle(style)
+	defer driver.(*TifDriver).setStyle(nil)
+
+	//white multiplied by the shade is the hillshade itself
+	img, err := driver.Render(testBBox(width, height), width, height)
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, 3, 3), gray(uint8(math.Round((math.Sqrt(0.5)+0.5)/math.Sqrt(2)*255))))
+
+	//an unset strength fully blends the hillshade, like the default relief
+	img, err = driver.Render(testBBox(width, height), width, height, WithRelief(models.Relief{}))
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, 3, 3), gray(uint8(math.Round((math.Sqrt(0.5)+0.5)/math.Sqrt(2)*255))))
+
+	img, err = driver.Render(testBBox(width, height), width, height, WithRelief(models.Relief{Strength: 0.5}))
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, 3, 3), gray(uint8(math.Round((1+(math.Sqrt(0.5)+0.5)/math.Sqrt(2))/2*255))))
+}
+
+func TestRenderSlope(t *testing.T) {
+	const width, height = 8, 8
+
+	//45 degrees slopes facing west, the pixels being 10 meters wide
+	path := createTestRaster(t, "dem.tif", godal.Float32, width, height, slope(width, height, testPixelSize))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("DEGREES", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithSlope(models.Slope{}))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(128))
+	})
+
+	t.Run("PERCENT", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithSlope(models.Slope{Unit: models.SlopePercent}))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(255))
+	})
+
+	t.Run("COLOR MAP", func(t *testing.T) {
+		style := &models.RasterStyle{
+			Mode: models.ModeSlope,
+			ColorMap: []models.ColorMapEntry{
+				{Color: "#00FF00", Quantity: 30, Opacity: 1},
+				{Color: "#FF0000", Quantity: 90, Opacity: 1},
+			},
+		}
+		driver.(*TifDriver).setStyle(style)
+		defer driver.(*TifDriver).setStyle(nil)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), color.NRGBA{R: 255, A: 255})
+	})
+
+	t.Run("MERCATOR LATITUDE", func(t *testing.T) {
+		//at 60 degrees north, the 10 meters wide Web Mercator pixels are 5 meters wide on the ground, so 45 degrees slopes rise by 5 meters
+		top := tiles.EarthRadius*math.Log(math.Tan(math.Pi/4+math.Pi/6)) + height*testPixelSize/2
+		gt := [6]float64{0, testPixelSize, 0, top, 0, -testPixelSize}
+		path := createTestRasterWithGeoTransform(t, "north.tif", 3857, gt, godal.Float32, width, height, slope(width, height, testPixelSize/2))
+		north, err := Load(path)
+		assert.NilError(t, err)
+		defer north.Release()
+
+		bbox := [4]float64{0, top - height*testPixelSize, width * testPixelSize, top}
+		img, err := north.Render(bbox, width, height, WithSlope(models.Slope{}))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(128))
+	})
+
+	t.Run("ASPECT", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithAspect())
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3), gray(uint8(math.Round(270.0/360*255))))
+
+		//flat areas are transparent
+		flat := createTestRaster(t, "flat.tif", godal.Float32, width, height, make([]float64, width*height))
+		flatDriver, err := Load(flat)
+		assert.NilError(t, err)
+		defer flatDriver.Release()
+
+		img, err = flatDriver.Render(testBBox(width, height), width, height, WithAspect())
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 3, 3).A, uint8(0))
+	})
+}
@@ -3,11 +3,13 @@ package raster
 import (
 	"fmt"
 	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/expr"
 	"github.com/canghel3/raster2image/models"
 	"github.com/canghel3/raster2image/render"
+	"github.com/canghel3/raster2image/tiles"
 	"image"
-	"log"
 	"math"
+	"strconv"
 	"sync"
 )
 
@@ -17,7 +19,27 @@ type TifDriver struct {
 	dataset *godal.Dataset
 	min     float64
 	max     float64
-	style   *models.RasterStyle
+	//per band min and max, used to stretch multi band rasters that are not 8-bit
+	ranges [][2]float64
+	style  *models.RasterStyle
+	//overrides the NoData value of the bands when not nil
+	noData *float64
+	//overviews to build when loading, nil if none were requested
+	overviews *overviewConfig
+	//whether an SRS is the dataset's own, by SRS
+	srsCache sync.Map
+	//extent of the dataset, by SRS
+	extentCache sync.Map
+	//statistics of the bands, by band and options
+	statistics sync.Map
+	//band math expression of WithExpression, the style's is used when empty
+	expressionSource string
+	//expression rendered instead of the bands, nil if none
+	expression *expr.Expression
+	//color tables of the paletted bands as color maps, nil for the other bands, by band index, read at load
+	palettes []*models.RasterStyle
+	//whether the raster has 2 bands and the second one is interpreted as alpha
+	grayAlpha bool
 }
 
 type TifDriverData struct {
@@ -25,260 +47,398 @@ type TifDriverData struct {
 	Dataset *godal.Dataset
 	Min     float64
 	Max     float64
+	Ranges  [][2]float64
 	Style   *models.RasterStyle
 }
 
 func NewTifDriver(data TifDriverData) Driver {
+	bands := data.Dataset.Bands()
 	return &TifDriver{
-		name:    data.Name,
-		dataset: data.Dataset,
-		max:     data.Max,
-		min:     data.Min,
-		style:   data.Style,
+		name:      data.Name,
+		dataset:   data.Dataset,
+		max:       data.Max,
+		min:       data.Min,
+		ranges:    data.Ranges,
+		style:     data.Style,
+		grayAlpha: len(bands) == 2 && bands[1].ColorInterp() == godal.CIAlpha,
 	}
 }
 
-func (td *TifDriver) Render(bbox [4]float64, width, height uint) (image.Image, error) {
-	switch len(td.dataset.Bands()) {
-	case 1:
-		return td.renderSingleBandV2(bbox, width, height)
-	case 2:
-		return nil, fmt.Errorf("cannot render raster %s with 2 Bands", td.name)
-	case 3:
-		//rgb
-	case 4:
-		return nil, fmt.Errorf("cannot render raster %s with 4 Bands", td.name)
+func (td *TifDriver) Render(bbox [4]float64, width, height uint, options ...RenderOption) (image.Image, error) {
+	ro := newRenderOptions(options...)
+	if !ro.resampling.valid() {
+		return nil, fmt.Errorf("unknown resampling algorithm %q", ro.resampling)
 	}
 
-	return nil, nil
-}
-
-func (td *TifDriver) renderSingleBandV2(bbox [4]float64, width, height uint) (image.Image, error) {
-	switches := []string{
-		"-te", fmt.Sprintf("%f", bbox[0]), fmt.Sprintf("%f", bbox[1]), fmt.Sprintf("%f", bbox[2]), fmt.Sprintf("%f", bbox[3]),
-		"-te_srs", "EPSG:3857",
-		"-ts", fmt.Sprintf("%d", width), fmt.Sprintf("%d", height),
-		"-s_srs", "EPSG:3857",
-		"-t_srs", "EPSG:3857",
-		"-of", "MEM",
-	}
-
-	td.lock.Lock()
-	warped, err := td.dataset.Warp("", switches)
-	td.lock.Unlock()
+	channels, err := td.channels(ro)
 	if err != nil {
 		return nil, err
 	}
 
-	band := warped.Bands()[0]
-	var data = make([]float64, width*height)
-	td.lock.RLock()
-	err = band.Read(0, 0, data, int(width), int(height))
-	td.lock.RUnlock()
+	overlaps, err := td.overlaps(bbox, ro)
 	if err != nil {
 		return nil, err
 	}
+	if !overlaps {
+		if ro.outsideExtentError {
+			return nil, ErrOutsideExtent
+		}
+		return image.NewRGBA(image.Rect(0, 0, int(width), int(height))), nil
+	}
 
-	if td.style != nil {
-		//setStyle given, so use rgb renderer with the setStyle schema
-		rgb := render.NewRGBDrawer(data, int(width), int(height), render.StyleOption(*td.style))
-		return rgb.Draw()
+	mode := td.mode(ro)
+	if td.expression != nil {
+		if mode != models.ModeDefault {
+			return nil, fmt.Errorf("cannot render the expression of raster %s in %s mode", td.name, mode)
+		}
+		return td.renderExpression(bbox, width, height, ro)
 	}
 
-	grayscale := render.Grayscale(data, int(width), int(height), td.min, td.max)
-	return grayscale.Draw()
+	if mode != models.ModeDefault && channels.Gray == 0 {
+		return nil, fmt.Errorf("cannot render raster %s in %s mode from several bands", td.name, mode)
+	}
+
+	switch mode {
+	case models.ModeHillshade:
+		return td.renderHillshade(bbox, width, height, *channels, ro)
+	case models.ModeRelief:
+		return td.renderRelief(bbox, width, height, *channels, ro)
+	case models.ModeSlope:
+		return td.renderSlope(bbox, width, height, *channels, ro)
+	case models.ModeAspect:
+		return td.renderAspect(bbox, width, height, *channels, ro)
+	}
 
+	if channels.Gray > 0 {
+		return td.renderSingleBandV2(bbox, width, height, *channels, ro)
+	}
+
+	return td.renderComposite(bbox, width, height, *channels, ro)
 }
 
-func (td *TifDriver) renderSingleBand(bbox [4]float64, width, height uint) (image.Image, error) {
-	xOff, yOff, xSize, ySize, err := td.getOffsetsAndSize(bbox)
-	if err != nil {
-		return nil, err
+// RenderTile renders the Web Mercator tile at z/x/y. Tiles are addressed with the XYZ scheme, unless WithTMS is used.
+// The image is WithTileSize * WithScale pixels wide and high and always in EPSG:3857, whatever WithSRS says.
+func (td *TifDriver) RenderTile(z, x, y uint, options ...RenderOption) (image.Image, error) {
+	ro := newRenderOptions(options...)
+
+	tile := tiles.Tile{Z: z, X: x, Y: y}
+	if !tile.Valid() {
+		return nil, fmt.Errorf("tile %s does not exist", tile)
+	}
+	if ro.tms {
+		tile = tile.FlipY()
+	}
+
+	size := ro.tileSize * ro.scale
+	if size == 0 {
+		return nil, fmt.Errorf("invalid tile size %d", size)
+	}
+
+	options = append(options, WithSRS(tiles.SRS), WithBBoxSRS(tiles.SRS))
+	return td.Render(tile.Bounds(), size, size, options...)
+}
+
+// channels decides which bands make up the rendered image.
+// The render options take precedence over the style's raster-channels.
+// When neither is set, single band rasters are rendered through the grayscale or style renderer,
+// 2-band rasters the same way with the second band as alpha when it is interpreted as alpha (gray+alpha),
+// otherwise from their first band only,
+// 4-band rasters as RGBA and rasters with 3 or more bands as RGB from their first three bands.
+func (td *TifDriver) channels(ro *renderOptions) (*models.Channels, error) {
+	channels := ro.channels
+	if channels == nil && td.style != nil {
+		var err error
+		channels, err = models.ParseChannels(td.style.RasterChannels)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bandCount := len(td.dataset.Bands())
+	if channels == nil {
+		switch bandCount {
+		case 1:
+			channels = &models.Channels{Gray: 1}
+		case 2:
+			channels = &models.Channels{Gray: 1}
+			if td.grayAlpha {
+				channels.Alpha = 2
+			}
+		case 4:
+			channels = &models.Channels{Red: 1, Green: 2, Blue: 3, Alpha: 4}
+		default:
+			channels = &models.Channels{Red: 1, Green: 2, Blue: 3}
+		}
+	}
+
+	for _, band := range channels.Bands() {
+		if band < 1 || band > bandCount {
+			return nil, fmt.Errorf("band %d does not exist in raster %s with %d Bands", band, td.name, bandCount)
+		}
+	}
+
+	return channels, nil
+}
+
+// renderSingleBandV2 renders the gray band of channels with the style's color map if there is one, with the color table
+// of the band when it is paletted, or as grayscale otherwise. Paletted bands are resampled with ResampleNearest, whatever WithResampling says.
+// When channels has an alpha band, it drives the transparency of the image.
+// NoData pixels and pixels outside the raster are transparent.
+func (td *TifDriver) renderSingleBandV2(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	//interpolating the indexes of a color table makes up classes, so paletted bands always take the closest pixel
+	if td.palette(channels.Gray-1) != nil && ro.resampling != ResampleNearest {
+		nearest := *ro
+		nearest.resampling = ResampleNearest
+		ro = &nearest
 	}
 
-	band := td.dataset.Bands()[0]
-	var data = make([]float64, xSize*ySize)
-	td.lock.RLock()
-	err = band.Read(xOff, yOff, data, xSize, ySize)
-	td.lock.RUnlock()
+	bands := channels.Bands()
+	data, coverage, err := td.fetch(bbox, width, height, ro, bands...)
 	if err != nil {
 		return nil, err
 	}
 
-	// If the requested output size differs from xSize, ySize, we must resample
-	// If width == xSize && height == ySize, no resampling needed
-	finalWidth := int(width)
-	finalHeight := int(height)
-	var dataToDraw []float64
-	if finalWidth != xSize || finalHeight != ySize {
-		log.Println("resampling")
-		dataToDraw = nearestResample(data, xSize, ySize, finalWidth, finalHeight)
+	var drawer render.Drawer
+	if td.style != nil && len(td.style.ColorMap) > 0 {
+		//setStyle given, so use rgb renderer with the setStyle schema
+		drawer = render.NewRGBDrawer(data[0], int(width), int(height), render.StyleOption(*td.style))
+	} else if palette := td.palette(channels.Gray - 1); palette != nil {
+		drawer = render.NewRGBDrawer(data[0], int(width), int(height), render.StyleOption(*palette))
 	} else {
-		log.Println("NOT RESAMPLING")
-		dataToDraw = data
+		stretch, err := td.stretch(channels.Gray-1, ro)
+		if err != nil {
+			return nil, err
+		}
+		drawer = render.StretchedGrayscale(data[0], int(width), int(height), stretch)
 	}
 
-	if td.style != nil {
-		//setStyle given, so use rgb renderer with the setStyle schema
-		rgb := render.NewRGBDrawer(dataToDraw, int(width), int(height), render.StyleOption(*td.style))
-		return rgb.Draw()
+	if mask, ok := validityMask(coverage, data[0]); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
 	}
 
-	grayscale := render.Grayscale(dataToDraw, int(width), int(height), td.min, td.max)
-	return grayscale.Draw()
-}
-
-func (td *TifDriver) Release() error {
-	td.lock.Lock()
-	defer td.lock.Unlock()
-	return td.dataset.Close()
-}
+	if channels.Alpha > 0 {
+		drawer = render.NewAlphaDrawer(drawer, td.alphaChannel(channels.Alpha-1, data[1]), int(width), int(height))
+	}
 
-func (td *TifDriver) setStyle(style *models.RasterStyle) {
-	td.style = style
+	return drawer.Draw()
 }
 
-func (td *TifDriver) getOffsetsAndSize(bbox [4]float64) (xOff, yOff, xSize, ySize int, err error) {
-	gt, err := td.dataset.GeoTransform()
+// renderComposite renders the bands selected by channels as the red, green, blue and, optionally, alpha components of the image.
+// Color bands that are not 8-bit are stretched using their own min and max, the alpha band is scaled by its data type.
+// Pixels that are NoData in every band and pixels outside the raster are transparent.
+func (td *TifDriver) renderComposite(bbox [4]float64, width, height uint, channels models.Channels, ro *renderOptions) (image.Image, error) {
+	bands := channels.Bands()
+	data, coverage, err := td.fetch(bbox, width, height, ro, bands...)
 	if err != nil {
-		return 0, 0, 0, 0, err
+		return nil, err
 	}
 
-	minX, minY, maxX, maxY := bbox[0], bbox[1], bbox[2], bbox[3]
+	//the warped dataset only holds the selected bands, in the order they were selected
+	components := make([]render.Channel, len(bands))
+	for i, band := range bands {
+		components[i] = td.channel(band-1, data[i])
+	}
+	if channels.Alpha > 0 {
+		components[3] = td.alphaChannel(channels.Alpha-1, data[3])
+	}
 
-	// Convert from georeferenced to pixel space (floating indices)
-	xOffFloat := (minX - gt[0]) / gt[1]
-	yOffFloat := (maxY - gt[3]) / gt[5] // gt[5] is typically negative
-	xEndFloat := (maxX - gt[0]) / gt[1]
-	yEndFloat := (minY - gt[3]) / gt[5]
+	drawer := render.NewCompositeDrawer(components[0], components[1], components[2], int(width), int(height))
+	if mask, ok := validityMask(coverage, data[0], data[1], data[2]); ok {
+		drawer = render.NewAlphaDrawer(drawer, mask, int(width), int(height))
+	}
+	if channels.Alpha > 0 {
+		drawer = render.NewAlphaDrawer(drawer, components[3], int(width), int(height))
+	}
 
-	// Snap to integral pixel boundaries
-	xOffInt := int(math.Floor(xOffFloat))
-	yOffInt := int(math.Floor(yOffFloat))
-	xEndInt := int(math.Ceil(xEndFloat))
-	yEndInt := int(math.Ceil(yEndFloat))
+	return drawer.Draw()
+}
+
+// warp reprojects the given bands (starting from 1) of the dataset to the bbox into an in-memory dataset of width x height pixels.
+// The bbox is expressed in the bbox SRS of the render options and the output is in their target SRS.
+// The source SRS is the dataset's own, EPSG:3857 is assumed only for datasets without one.
+// Only the given bands are read from the dataset and they keep the given order. No bands means all of them.
+// The warped dataset has an extra alpha band at the end, which is 0 for NoData pixels and pixels outside the raster.
+// The caller is responsible for closing the returned dataset.
+func (td *TifDriver) warp(bbox [4]float64, width, height uint, ro *renderOptions, bands ...int) (*godal.Dataset, error) {
+	switches := []string{
+		"-te", fmt.Sprintf("%f", bbox[0]), fmt.Sprintf("%f", bbox[1]), fmt.Sprintf("%f", bbox[2]), fmt.Sprintf("%f", bbox[3]),
+		"-te_srs", ro.bboxSRS,
+		"-ts", fmt.Sprintf("%d", width), fmt.Sprintf("%d", height),
+		"-t_srs", ro.srs,
+		"-r", string(ro.resampling),
+		//alpha bands of the raster are warped like any other band, the coverage is tracked by the destination alpha
+		"-nosrcalpha",
+		"-dstalpha",
+		"-of", "MEM",
+	}
 
-	// Recompute the snapped bounding box from these integral pixel coords
-	// This ensures the bbox now aligns exactly with pixel edges.
-	snappedMinX := gt[0] + float64(xOffInt)*gt[1]
-	snappedMaxY := gt[3] + float64(yOffInt)*gt[5]
-	snappedMaxX := gt[0] + float64(xEndInt)*gt[1]
-	snappedMinY := gt[3] + float64(yEndInt)*gt[5]
+	if td.noData != nil {
+		switches = append(switches, "-srcnodata", strconv.FormatFloat(*td.noData, 'g', -1, 64))
+	}
 
-	// Now recompute offsets and size using the snapped bbox
-	// This step ensures consistency:
-	newXOffFloat := (snappedMinX - gt[0]) / gt[1]
-	newYOffFloat := (snappedMaxY - gt[3]) / gt[5]
-	newXEndFloat := (snappedMaxX - gt[0]) / gt[1]
-	newYEndFloat := (snappedMinY - gt[3]) / gt[5]
+	if td.dataset.Projection() == "" {
+		switches = append(switches, "-s_srs", DefaultSRS)
+	}
 
-	xOff = int(math.Floor(newXOffFloat))
-	yOff = int(math.Floor(newYOffFloat))
-	xEnd := int(math.Ceil(newXEndFloat))
-	yEnd := int(math.Ceil(newYEndFloat))
+	td.lock.Lock()
+	defer td.lock.Unlock()
 
-	xSize = xEnd - xOff
-	ySize = yEnd - yOff
+	source := td.dataset
+	if !td.allBands(bands) {
+		//a virtual dataset with just the selected bands avoids warping the ones that are not needed
+		selection := []string{"-of", "VRT"}
+		for _, band := range bands {
+			selection = append(selection, "-b", strconv.Itoa(band))
+		}
 
-	// Clamp values to dataset boundaries
-	dsWidth := td.dataset.Structure().SizeX
-	dsHeight := td.dataset.Structure().SizeY
+		vrt, err := td.dataset.Translate("", selection)
+		if err != nil {
+			return nil, err
+		}
+		defer vrt.Close()
 
-	if xOff < 0 {
-		xSize += xOff
-		xOff = 0
+		source = vrt
 	}
-	if yOff < 0 {
-		ySize += yOff
-		yOff = 0
+
+	return source.Warp("", switches)
+}
+
+// read reads every band of a dataset returned by warp. The alpha band added by the warp is returned separately as coverage.
+func (td *TifDriver) read(warped *godal.Dataset, width, height uint) (data [][]float64, coverage []float64, err error) {
+	bands := warped.Bands()
+	data = make([][]float64, len(bands))
+	for i, band := range bands {
+		data[i] = make([]float64, width*height)
+		td.lock.RLock()
+		err = band.Read(0, 0, data[i], int(width), int(height))
+		td.lock.RUnlock()
+		if err != nil {
+			return nil, nil, err
+		}
 	}
-	if xOff+xSize > dsWidth {
-		xSize = dsWidth - xOff
+
+	return data[:len(data)-1], data[len(data)-1], nil
+}
+
+// allBands reports whether bands selects every band of the dataset in its original order.
+func (td *TifDriver) allBands(bands []int) bool {
+	if len(bands) == 0 {
+		return true
 	}
-	if yOff+ySize > dsHeight {
-		ySize = dsHeight - yOff
+
+	if len(bands) != len(td.dataset.Bands()) {
+		return false
 	}
 
-	if xSize <= 0 || ySize <= 0 {
-		return 0, 0, 0, 0, fmt.Errorf("requested area is outside the raster extent")
+	for i, band := range bands {
+		if band != i+1 {
+			return false
+		}
 	}
 
-	return xOff, yOff, xSize, ySize, nil
+	return true
 }
 
-func nearestResample(src []float64, srcWidth, srcHeight, dstWidth, dstHeight int) []float64 {
-	if dstWidth == srcWidth && dstHeight == srcHeight {
-		// No resampling needed, just return a copy
-		out := make([]float64, len(src))
-		copy(out, src)
-		return out
+// channel wraps the data read from the band at index i into a render.Channel.
+// 8-bit bands are used as they are, every other data type (signed and unsigned integers of any size and floats)
+// is stretched from the band's min and max, which ignore NaN and infinite values.
+func (td *TifDriver) channel(i int, data []float64) render.Channel {
+	if td.dataset.Bands()[i].Structure().DataType == godal.Byte {
+		return render.Channel{Data: data, Min: 0, Max: 255}
 	}
 
-	out := make([]float64, dstWidth*dstHeight)
-	// Compute ratios
-	xRatio := float64(srcWidth) / float64(dstWidth)
-	yRatio := float64(srcHeight) / float64(dstHeight)
+	min, max := td.bandRange(i)
+	return render.Channel{Data: data, Min: min, Max: max}
+}
 
-	for y := 0; y < dstHeight; y++ {
-		for x := 0; x < dstWidth; x++ {
-			// Map the output pixel back to source coordinates
-			srcX := int(float64(x) * xRatio)
-			srcY := int(float64(y) * yRatio)
+// alphaChannel wraps the data read from the alpha band at index i into a render.Channel.
+// Alpha is never stretched like the color bands, 0 is transparent and the max of the band's data type is opaque.
+// Float bands are opaque at 1.
+func (td *TifDriver) alphaChannel(i int, data []float64) render.Channel {
+	return render.Channel{Data: data, Min: 0, Max: opaque(td.dataset.Bands()[i].Structure().DataType)}
+}
 
-			// Clamp to avoid any floating rounding issues (shouldn't normally happen)
-			if srcX >= srcWidth {
-				srcX = srcWidth - 1
-			}
-			if srcY >= srcHeight {
-				srcY = srcHeight - 1
+// opaque returns the alpha value of an opaque pixel for the given data type.
+func opaque(dtype godal.DataType) float64 {
+	switch dtype {
+	case godal.Int8:
+		return math.MaxInt8
+	case godal.UInt16:
+		return math.MaxUint16
+	case godal.Int16:
+		return math.MaxInt16
+	case godal.UInt32:
+		return math.MaxUint32
+	case godal.Int32:
+		return math.MaxInt32
+	case godal.Float32, godal.Float64:
+		return 1
+	}
+
+	return math.MaxUint8
+}
+
+// validityMask returns an alpha channel that hides the pixels left uncovered by the warp (NoData or outside the raster)
+// and the pixels which are NaN or infinite in any of the given bands.
+// It returns false when every pixel is valid, in which case no mask is needed.
+func validityMask(coverage []float64, bands ...[]float64) (render.Channel, bool) {
+	mask := make([]float64, len(coverage))
+	copy(mask, coverage)
+
+	valid := true
+	for i := range mask {
+		for _, band := range bands {
+			if math.IsNaN(band[i]) || math.IsInf(band[i], 0) {
+				mask[i] = 0
 			}
+		}
 
-			out[y*dstWidth+x] = src[srcY*srcWidth+srcX]
+		if mask[i] < 255 {
+			valid = false
 		}
 	}
 
-	return out
+	return render.Channel{Data: mask, Min: 0, Max: 255}, !valid
 }
 
-func bilinearResample(src []float64, srcWidth, srcHeight, dstWidth, dstHeight int) []float64 {
-	out := make([]float64, dstWidth*dstHeight)
-	xRatio := float64(srcWidth-1) / float64(dstWidth-1)
-	yRatio := float64(srcHeight-1) / float64(dstHeight-1)
-
-	for y := 0; y < dstHeight; y++ {
-		for x := 0; x < dstWidth; x++ {
-			// Map dst pixel to fractional src coordinates
-			srcX := float64(x) * xRatio
-			srcY := float64(y) * yRatio
-
-			xFloor := int(math.Floor(srcX))
-			yFloor := int(math.Floor(srcY))
-			xCeil := xFloor + 1
-			yCeil := yFloor + 1
+// bandRange returns the min and max of the band at index i.
+func (td *TifDriver) bandRange(i int) (min, max float64) {
+	if i < len(td.ranges) {
+		return td.ranges[i][0], td.ranges[i][1]
+	}
 
-			if xCeil >= srcWidth {
-				xCeil = srcWidth - 1
-			}
-			if yCeil >= srcHeight {
-				yCeil = srcHeight - 1
-			}
+	return td.min, td.max
+}
 
-			topLeft := src[yFloor*srcWidth+xFloor]
-			topRight := src[yFloor*srcWidth+xCeil]
-			bottomLeft := src[yCeil*srcWidth+xFloor]
-			bottomRight := src[yCeil*srcWidth+xCeil]
+func (td *TifDriver) Release() error {
+	td.lock.Lock()
+	defer td.lock.Unlock()
+	return td.dataset.Close()
+}
 
-			xFrac := srcX - float64(xFloor)
-			yFrac := srcY - float64(yFloor)
+func (td *TifDriver) setStyle(style *models.RasterStyle) {
+	td.style = style
+}
 
-			// Interpolate in X
-			topVal := topLeft + (topRight-topLeft)*xFrac
-			bottomVal := bottomLeft + (bottomRight-bottomLeft)*xFrac
+func (td *TifDriver) setNoData(noData float64) {
+	td.noData = &noData
+}
 
-			// Interpolate in Y
-			out[y*dstWidth+x] = topVal + (bottomVal-topVal)*yFrac
+// computeRanges computes the min and max of the bands, ignoring NoData, from their approximate statistics,
+// so that large rasters are not read whole. No percentiles are computed, since only the min and max are needed.
+func (td *TifDriver) computeRanges() error {
+	td.ranges = make([][2]float64, len(td.dataset.Bands()))
+	for i := range td.ranges {
+		statistics, err := td.Statistics(i+1, Approximate(), WithPercentiles())
+		if err != nil {
+			return err
 		}
+		td.ranges[i] = [2]float64{statistics.Min, statistics.Max}
 	}
 
-	return out
+	td.min, td.max = td.ranges[0][0], td.ranges[0][1]
+	for _, r := range td.ranges[1:] {
+		td.min, td.max = math.Min(td.min, r[0]), math.Max(td.max, r[1])
+	}
+	return nil
 }
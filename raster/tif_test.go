@@ -0,0 +1,563 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPixelSize = 10
+
+// createTestRaster writes a north-up GeoTIFF in EPSG:3857 with the given bands into a temporary directory.
+// Each band holds width*height values, row by row. The raster covers testBBox(width, height).
+func createTestRaster(t testing.TB, name string, dtype godal.DataType, width, height int, bands ...[]float64) string {
+	return createTestRasterInSRS(t, name, 3857, dtype, width, height, bands...)
+}
+
+// createTestRasterInSRS is like createTestRaster, with the raster in the SRS of the given EPSG code.
+func createTestRasterInSRS(t testing.TB, name string, epsg int, dtype godal.DataType, width, height int, bands ...[]float64) string {
+	gt := [6]float64{0, testPixelSize, 0, float64(height * testPixelSize), 0, -testPixelSize}
+	return createTestRasterWithGeoTransform(t, name, epsg, gt, dtype, width, height, bands...)
+}
+
+// createTestRasterWithGeoTransform is like createTestRasterInSRS, with the raster placed by the geotransform gt.
+func createTestRasterWithGeoTransform(t testing.TB, name string, epsg int, gt [6]float64, dtype godal.DataType, width, height int, bands ...[]float64) string {
+	path := filepath.Join(t.TempDir(), name)
+	ds, err := godal.Create(godal.GTiff, path, len(bands), dtype, width, height)
+	assert.NilError(t, err)
+
+	sr, err := godal.NewSpatialRefFromEPSG(epsg)
+	assert.NilError(t, err)
+	defer sr.Close()
+
+	assert.NilError(t, ds.SetSpatialRef(sr))
+	assert.NilError(t, ds.SetGeoTransform(gt))
+	for i, band := range bands {
+		assert.NilError(t, ds.Bands()[i].Write(0, 0, band, width, height))
+	}
+	assert.NilError(t, ds.Close())
+
+	t.Cleanup(func() {
+		Release(path)
+	})
+
+	return path
+}
+
+func testBBox(width, height int) [4]float64 {
+	return [4]float64{0, 0, float64(width * testPixelSize), float64(height * testPixelSize)}
+}
+
+// ramp returns size values starting from start and increasing by step.
+func ramp(size int, start, step float64) []float64 {
+	data := make([]float64, size)
+	for i := range data {
+		data[i] = start + float64(i)*step
+	}
+	return data
+}
+
+func nrgba(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestRenderRGB(t *testing.T) {
+	const width, height = 4, 1
+
+	red := []float64{255, 0, 0, 10}
+	green := []float64{0, 255, 0, 20}
+	blue := []float64{0, 0, 255, 30}
+	path := createTestRaster(t, "rgb.tif", godal.Byte, width, height, red, green, blue)
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	img, err := driver.Render(testBBox(width, height), width, height)
+	assert.NilError(t, err)
+
+	//8-bit bands are used as they are
+	assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 255, A: 255})
+	assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{G: 255, A: 255})
+	assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{B: 255, A: 255})
+	assert.Equal(t, nrgba(img, 3, 0), color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+}
+
+func TestRenderRGBA(t *testing.T) {
+	const width, height = 3, 1
+
+	red := []float64{255, 0, 0}
+	green := []float64{0, 255, 0}
+	blue := []float64{0, 0, 255}
+	alpha := []float64{255, 0, 128}
+	path := createTestRaster(t, "rgba.tif", godal.Byte, width, height, red, green, blue, alpha)
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("DEFAULT", func(t *testing.T) {
+		//the fourth band is the alpha
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 255, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{B: 255, A: 128})
+	})
+
+	t.Run("CHANNELS", func(t *testing.T) {
+		//bands mapped to the components without alpha are opaque
+		img, err := driver.Render(testBBox(width, height), width, height, WithChannels(3, 2, 1))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{G: 255, A: 255})
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 255, A: 255})
+	})
+
+	t.Run("ALPHA CHANNEL", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithChannels(3, 2, 1), WithAlphaChannel(4))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 255, A: 128})
+	})
+
+	t.Run("DATA TYPES", func(t *testing.T) {
+		//the alpha is scaled by the range of its data type, not stretched like the colors
+		tests := []struct {
+			name     string
+			dtype    godal.DataType
+			alpha    []float64
+			expected []uint8
+		}{
+			{"UINT16", godal.UInt16, []float64{65535, 65535, 65535}, []uint8{255, 255, 255}},
+			{"UINT16 PARTIAL", godal.UInt16, []float64{0, 255, 32768}, []uint8{0, 1, 128}},
+			{"FLOAT32", godal.Float32, []float64{1, 0, 0.5}, []uint8{255, 0, 128}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				path := createTestRaster(t, test.name+".tif", test.dtype, width, height, red, green, blue, test.alpha)
+				driver, err := Load(path)
+				assert.NilError(t, err)
+
+				img, err := driver.Render(testBBox(width, height), width, height)
+				assert.NilError(t, err)
+				for x, expected := range test.expected {
+					assert.Equal(t, nrgba(img, x, 0).A, expected)
+				}
+			})
+		}
+	})
+}
+
+func TestRenderStyleChannels(t *testing.T) {
+	const width, height = 3, 1
+
+	bands := [][]float64{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {10, 20, 30}}
+	path := createTestRaster(t, "channels.tif", godal.Byte, width, height, bands...)
+
+	load := func(t *testing.T, channels string) Driver {
+		style := filepath.Join(t.TempDir(), "channels.css")
+		assert.NilError(t, os.WriteFile(style, []byte("raster {\n    raster-channels: "+channels+";\n}\n"), 0644))
+
+		driver, err := Load(path, WithStyle(style))
+		assert.NilError(t, err)
+		t.Cleanup(func() {
+			driver.Release()
+		})
+		return driver
+	}
+
+	t.Run("FALSE COLOR", func(t *testing.T) {
+		img, err := load(t, "4 3 2").Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 10, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{R: 20, B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 30, G: 255, A: 255})
+	})
+
+	t.Run("SINGLE BAND", func(t *testing.T) {
+		img, err := load(t, "2").Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{A: 255})
+	})
+
+	t.Run("OPTIONS FIRST", func(t *testing.T) {
+		//the render options take precedence over the style
+		img, err := load(t, "4 3 2").Render(testBBox(width, height), width, height, WithChannels(1, 2, 3))
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 255, A: 255})
+	})
+}
+
+func TestRenderGrayAlpha(t *testing.T) {
+	const width, height = 3, 1
+
+	gray := []float64{0, 128, 255}
+	alpha := []float64{255, 0, 255}
+	path := createTestRaster(t, "gray_alpha.tif", godal.Byte, width, height, gray, alpha)
+	ds, err := godal.Open(path, godal.Update())
+	assert.NilError(t, err)
+	assert.NilError(t, ds.Bands()[1].SetColorInterp(godal.CIAlpha))
+	assert.NilError(t, ds.Close())
+
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("GRAYSCALE", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+		assert.Equal(t, nrgba(img, 1, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	})
+
+	t.Run("COLOR MAP", func(t *testing.T) {
+		driver.(*TifDriver).setStyle(&models.RasterStyle{
+			ColorMap: []models.ColorMapEntry{
+				{Color: "#FF0000", Quantity: 0, Opacity: 1},
+				{Color: "#0000FF", Quantity: 255, Opacity: 1},
+			},
+		})
+		defer driver.(*TifDriver).setStyle(nil)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 255, A: 255})
+		assert.Equal(t, nrgba(img, 1, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{B: 255, A: 255})
+	})
+
+	t.Run("SECOND BAND NOT ALPHA", func(t *testing.T) {
+		//a second band that is not interpreted as alpha does not mask the first one
+		path := createTestRaster(t, "two_bands.tif", godal.Byte, width, height, gray, alpha)
+		driver, err := Load(path)
+		assert.NilError(t, err)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+		assert.Equal(t, nrgba(img, 2, 0), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	})
+}
+
+func TestRenderDataTypes(t *testing.T) {
+	const width, height = 4, 4
+
+	tests := []struct {
+		name  string
+		dtype godal.DataType
+		start float64
+		step  float64
+	}{
+		{"BYTE", godal.Byte, 10, 10},
+		{"UINT16", godal.UInt16, 1000, 1500},
+		{"INT16", godal.Int16, -8000, 1000},
+		{"UINT32", godal.UInt32, 100000, 250000},
+		{"INT32", godal.Int32, -1000000, 125000},
+		{"FLOAT32", godal.Float32, -1.5, 0.25},
+		{"FLOAT64", godal.Float64, 250.125, 0.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := createTestRaster(t, test.name+".tif", test.dtype, width, height, ramp(width*height, test.start, test.step))
+			driver, err := Load(path)
+			assert.NilError(t, err)
+
+			img, err := driver.Render(testBBox(width, height), width, height)
+			assert.NilError(t, err)
+
+			//the lowest value is black and the highest value is white, whatever the data type
+			assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+			assert.Equal(t, nrgba(img, width-1, height-1), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			assert.Check(t, nrgba(img, 1, 1).R > nrgba(img, 0, 1).R)
+		})
+	}
+
+	t.Run("NAN AND INF", func(t *testing.T) {
+		for _, dtype := range []godal.DataType{godal.Float32, godal.Float64} {
+			data := ramp(width*height, 0, 1)
+			data[1] = math.NaN()
+			data[2] = math.Inf(1)
+			data[3] = math.Inf(-1)
+
+			path := createTestRaster(t, dtype.String()+"_nan.tif", dtype, width, height, data)
+			driver, err := Load(path)
+			assert.NilError(t, err)
+
+			img, err := driver.Render(testBBox(width, height), width, height)
+			assert.NilError(t, err)
+
+			//NaN and infinite values are transparent and do not affect the stretch
+			for x := 1; x < 4; x++ {
+				assert.Equal(t, nrgba(img, x, 0).A, uint8(0))
+			}
+			assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+			assert.Equal(t, nrgba(img, width-1, height-1), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	})
+
+	t.Run("MULTIBAND UINT16", func(t *testing.T) {
+		red := ramp(width*height, 0, 4000)
+		green := ramp(width*height, 30000, 100)
+		blue := ramp(width*height, 60000, 0)
+		path := createTestRaster(t, "rgb_uint16.tif", godal.UInt16, width, height, red, green, blue)
+		driver, err := Load(path)
+		assert.NilError(t, err)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+
+		//every band is stretched from its own min and max
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+		assert.Equal(t, nrgba(img, width-1, height-1), color.NRGBA{R: 255, G: 255, B: 0, A: 255})
+	})
+}
+
+func TestRenderNoData(t *testing.T) {
+	const width, height = 4, 4
+
+	data := ramp(width*height, 100, 10)
+	data[0] = -9999
+
+	t.Run("FROM BAND", func(t *testing.T) {
+		path := createTestRaster(t, "nodata.tif", godal.Int16, width, height, data)
+		ds, err := godal.Open(path, godal.Update())
+		assert.NilError(t, err)
+		assert.NilError(t, ds.Bands()[0].SetNoData(-9999))
+		assert.NilError(t, ds.Close())
+
+		driver, err := Load(path)
+		assert.NilError(t, err)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+
+		//the NoData pixel is transparent and the stretch starts from the lowest valid value
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{A: 255})
+		assert.Equal(t, nrgba(img, width-1, height-1), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	})
+
+	t.Run("OVERRIDE", func(t *testing.T) {
+		path := createTestRaster(t, "nodata_override.tif", godal.Int16, width, height, data)
+		driver, err := Load(path, WithNoData(-9999))
+		assert.NilError(t, err)
+
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, 1, 0), color.NRGBA{A: 255})
+	})
+
+	t.Run("OUTSIDE EXTENT", func(t *testing.T) {
+		path := createTestRaster(t, "outside.tif", godal.Byte, width, height, ramp(width*height, 0, 10))
+		driver, err := Load(path)
+		assert.NilError(t, err)
+
+		//the right half of the bbox is outside the raster
+		bbox := testBBox(width, height)
+		bbox[2] += float64(width * testPixelSize)
+		img, err := driver.Render(bbox, width*2, height)
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(255))
+		assert.Equal(t, nrgba(img, width, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, width*2-1, height-1).A, uint8(0))
+	})
+}
+
+func TestRenderSRS(t *testing.T) {
+	const width, height = 4, 4
+
+	//a raster in UTM 33N, whose coordinates are nowhere near the same place in EPSG:3857
+	path := createTestRasterInSRS(t, "utm.tif", 32633, godal.Byte, width, height, ramp(width*height, 0, 10))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("NATIVE", func(t *testing.T) {
+		img, err := driver.Render(testBBox(width, height), width, height, WithSRS("EPSG:32633"))
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{A: 255})
+		assert.Equal(t, nrgba(img, width-1, height-1), color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	})
+
+	t.Run("BBOX IN DIFFERENT SRS", func(t *testing.T) {
+		//the bbox is in UTM but the image is in EPSG:3857, so the raster is reprojected and still covers the image
+		img, err := driver.Render(testBBox(width, height), width, height, WithSRS("EPSG:3857"), WithBBoxSRS("EPSG:32633"))
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, width/2, height/2).A, uint8(255))
+	})
+
+	t.Run("DEFAULT SRS", func(t *testing.T) {
+		//the same bbox in EPSG:3857 is far away from the raster
+		img, err := driver.Render(testBBox(width, height), width, height)
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, width/2, height/2).A, uint8(0))
+	})
+}
+
+func TestRenderTile(t *testing.T) {
+	const width, height = 4, 4
+
+	//the raster covers 40x40 meters north-east of 0,0, more than a tile at zoom 20
+	path := createTestRaster(t, "tile.tif", godal.Byte, width, height, ramp(width*height, 0, 10))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	const z, x, y = 20, 1 << 19, 1<<19 - 1
+
+	t.Run("XYZ", func(t *testing.T) {
+		img, err := driver.RenderTile(z, x, y)
+		assert.NilError(t, err)
+		assert.Equal(t, img.Bounds(), image.Rect(0, 0, 256, 256))
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(255))
+		assert.Equal(t, nrgba(img, 255, 255).A, uint8(255))
+	})
+
+	t.Run("TMS", func(t *testing.T) {
+		img, err := driver.RenderTile(z, x, 1<<z-1-y, WithTMS())
+		assert.NilError(t, err)
+		assert.Equal(t, nrgba(img, 128, 128).A, uint8(255))
+	})
+
+	t.Run("RETINA", func(t *testing.T) {
+		img, err := driver.RenderTile(z, x, y, WithTileSize(512), WithScale(2))
+		assert.NilError(t, err)
+		assert.Equal(t, img.Bounds(), image.Rect(0, 0, 1024, 1024))
+	})
+
+	t.Run("INVALID", func(t *testing.T) {
+		_, err := driver.RenderTile(1, 2, 0)
+		assert.ErrorContains(t, err, "does not exist")
+	})
+}
+
+func TestRenderPartialCoverage(t *testing.T) {
+	const width, height = 4, 4
+	path := createTestRaster(t, "partial.tif", godal.Byte, width, height, ramp(width*height, 0, 10))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	t.Run("PADDED", func(t *testing.T) {
+		//the bbox is shifted left by half the raster, so the raster fills the right half of the image
+		bbox := testBBox(width, height)
+		bbox[0] -= float64(width * testPixelSize / 2)
+		bbox[2] -= float64(width * testPixelSize / 2)
+		img, err := driver.Render(bbox, width, height)
+		assert.NilError(t, err)
+
+		assert.Equal(t, nrgba(img, 0, 0).A, uint8(0))
+		assert.Equal(t, nrgba(img, width/2-1, height-1).A, uint8(0))
+		//the first columns of the raster, not stretched over the whole image
+		assert.Equal(t, nrgba(img, width/2, 0).A, uint8(255))
+		assert.Equal(t, nrgba(img, width/2, 0).R, uint8(0))
+		assert.Equal(t, nrgba(img, width/2+1, 0).R, uint8(math.Round(10*255/150.0)))
+	})
+
+	bbox := testBBox(width, height)
+	bbox[0] += float64(width * testPixelSize * 2)
+	bbox[2] += float64(width * testPixelSize * 2)
+
+	t.Run("OUTSIDE", func(t *testing.T) {
+		img, err := driver.Render(bbox, width, height)
+		assert.NilError(t, err)
+		assert.Equal(t, img.Bounds(), image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				assert.Equal(t, nrgba(img, x, y).A, uint8(0))
+			}
+		}
+	})
+
+	t.Run("OUTSIDE ERROR", func(t *testing.T) {
+		_, err := driver.Render(bbox, width, height, WithOutsideExtentError())
+		assert.ErrorIs(t, err, ErrOutsideExtent)
+	})
+}
+
+func TestRenderRotated(t *testing.T) {
+	const width, height = 4, 4
+
+	//rotated by 90 degrees: columns run north and rows run east
+	gt := [6]float64{0, 0, testPixelSize, 0, testPixelSize, 0}
+	path := createTestRasterWithGeoTransform(t, "rotated.tif", 3857, gt, godal.Byte, width, height, ramp(width*height, 0, 10))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+
+	//rotated rasters are warped
+	_, _, ok, err := driver.(*TifDriver).readWindow(testBBox(width, height), width, height, newRenderOptions(), 1)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	//the pixel of column 1 and row 2, which holds 90, is centered on 25, 15
+	img, err := driver.Render([4]float64{20, 10, 30, 20}, 1, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, 0, 0), color.NRGBA{R: 153, G: 153, B: 153, A: 255})
+
+	t.Run("EXTENT", func(t *testing.T) {
+		_, err := driver.Render([4]float64{50, 50, 60, 60}, 1, 1, WithOutsideExtentError())
+		assert.ErrorIs(t, err, ErrOutsideExtent)
+	})
+}
+
+func TestRenderStretch(t *testing.T) {
+	const width, height = 10, 10
+
+	//a single outlier would make every other pixel nearly black with a min-max stretch
+	data := ramp(width*height, 0, 1)
+	data[width*height-1] = 100000
+	path := createTestRaster(t, "stretch.tif", godal.Float32, width, height, data)
+	driver, err := Load(path)
+	assert.NilError(t, err)
+	bbox := testBBox(width, height)
+
+	img, err := driver.Render(bbox, width, height)
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, width-1, height-2).R, uint8(0))
+
+	img, err = driver.Render(bbox, width, height, WithStretch(models.Stretch{Type: models.StretchPercentile, Low: 0, High: 90}))
+	assert.NilError(t, err)
+	assert.Equal(t, nrgba(img, 0, 0).R, uint8(0))
+	assert.Equal(t, nrgba(img, width-1, height-2).R, uint8(255))
+	assert.Equal(t, nrgba(img, 5, 4).R, uint8(math.Round(45*255/89.1)))
+
+	t.Run("CURVE", func(t *testing.T) {
+		linear, err := driver.Render(bbox, width, height, WithStretch(models.Stretch{Type: models.StretchStdDev, StdDevs: 1}))
+		assert.NilError(t, err)
+		gamma, err := driver.Render(bbox, width, height, WithStretch(models.Stretch{Type: models.StretchStdDev, StdDevs: 1, Curve: models.CurveGamma, Gamma: 2}))
+		assert.NilError(t, err)
+		assert.Assert(t, nrgba(gamma, 5, 0).R > nrgba(linear, 5, 0).R)
+	})
+
+	t.Run("DEFAULTS", func(t *testing.T) {
+		//zero percentiles and standard deviations stand for the defaults instead of an all black image
+		for _, test := range []struct{ zero, defaults models.Stretch }{
+			{models.Stretch{Type: models.StretchPercentile}, models.Stretch{Type: models.StretchPercentile, Low: 2, High: 98}},
+			{models.Stretch{Type: models.StretchStdDev}, models.Stretch{Type: models.StretchStdDev, StdDevs: 2}},
+		} {
+			zero, err := driver.Render(bbox, width, height, WithStretch(test.zero))
+			assert.NilError(t, err)
+			defaults, err := driver.Render(bbox, width, height, WithStretch(test.defaults))
+			assert.NilError(t, err)
+			assert.DeepEqual(t, zero, defaults)
+			assert.Assert(t, nrgba(zero, width-1, height-2).R > 0)
+		}
+	})
+
+	t.Run("INVALID", func(t *testing.T) {
+		_, err := driver.Render(bbox, width, height, WithStretch(models.Stretch{Type: models.StretchPercentile, Low: 98, High: 2}))
+		assert.ErrorContains(t, err, "invalid stretch percentiles")
+	})
+}
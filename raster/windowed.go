@@ -0,0 +1,240 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"math"
+)
+
+// fetch returns the given bands (starting from 1) of the dataset over the bbox, at width x height pixels, and their coverage,
+// which is 0 for NoData pixels and pixels outside the raster and 255 elsewhere.
+// When the render options use the dataset's own SRS, the bbox is read straight from the dataset or its best overview,
+// otherwise the dataset is warped.
+func (td *TifDriver) fetch(bbox [4]float64, width, height uint, ro *renderOptions, bands ...int) (data [][]float64, coverage []float64, err error) {
+	if td.sameSRS(ro) {
+		data, coverage, ok, err := td.readWindow(bbox, width, height, ro, bands...)
+		if err != nil || ok {
+			return data, coverage, err
+		}
+	}
+
+	warped, err := td.warp(bbox, width, height, ro, bands...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer warped.Close()
+
+	return td.read(warped, width, height)
+}
+
+// sameSRS reports whether both the target and the bbox SRS of the render options are the dataset's own,
+// in which case the raster does not need to be reprojected.
+func (td *TifDriver) sameSRS(ro *renderOptions) bool {
+	return td.isDatasetSRS(ro.srs) && (ro.bboxSRS == ro.srs || td.isDatasetSRS(ro.bboxSRS))
+}
+
+// isDatasetSRS reports whether srs is the SRS of the dataset, EPSG:3857 for datasets without one.
+// The answer is cached, since parsing an SRS is slow compared to reading a tile.
+func (td *TifDriver) isDatasetSRS(srs string) bool {
+	if same, ok := td.srsCache.Load(srs); ok {
+		return same.(bool)
+	}
+
+	same := false
+	target, err := godal.NewSpatialRef(srs)
+	if err == nil {
+		defer target.Close()
+
+		own, err := td.spatialRef()
+		if err == nil {
+			same = own.IsSame(target)
+			own.Close()
+		}
+	}
+
+	td.srsCache.Store(srs, same)
+	return same
+}
+
+// readWindow reads the given bands over the bbox from the dataset, or from the coarsest overview which is still at least
+// as fine as the output, and resamples them to width x height pixels in Go.
+// It returns false when the bbox cannot be read as a window of the raster, in which case the raster has to be warped.
+func (td *TifDriver) readWindow(bbox [4]float64, width, height uint, ro *renderOptions, bands ...int) ([][]float64, []float64, bool, error) {
+	if width == 0 || height == 0 {
+		return nil, nil, false, nil
+	}
+
+	gt, err := td.dataset.GeoTransform()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	//the pixels of rotated, sheared or flipped rasters are not aligned with the bbox, they have to be warped
+	if !geoTransform(gt).northUp() {
+		return nil, nil, false, nil
+	}
+
+	win, ok := pixelWindow(gt, bbox)
+	if !ok || win.width() <= 0 || win.height() <= 0 {
+		return nil, nil, false, nil
+	}
+
+	if len(bands) == 0 {
+		for i := range td.dataset.Bands() {
+			bands = append(bands, i+1)
+		}
+	}
+
+	rasterBands := td.dataset.Bands()
+	//output pixels are ratio raster pixels wide, along the finer of the two axes
+	ratio := math.Min(win.width()/float64(width), win.height()/float64(height))
+	level := overviewFor(rasterBands[bands[0]-1], ratio)
+
+	for _, band := range bands {
+		if level >= len(rasterBands[band-1].Overviews()) {
+			//the bands do not have the same overviews, read them all at full resolution
+			level = -1
+		}
+	}
+
+	data, coverage, err := td.readWindowLevel(win, width, height, ro, level, bands)
+	return data, coverage, err == nil, err
+}
+
+// readWindowLevel reads the window, in pixels of the full resolution raster, from the given overview level of the bands,
+// -1 being the full resolution, and resamples it to width x height pixels.
+func (td *TifDriver) readWindowLevel(win window, width, height uint, ro *renderOptions, level int, bands []int) ([][]float64, []float64, error) {
+	structure := td.dataset.Structure()
+	rasterBands := td.dataset.Bands()
+
+	data := make([][]float64, len(bands))
+	for i, band := range bands {
+		source := rasterBands[band-1]
+		if level >= 0 {
+			source = source.Overviews()[level]
+		}
+
+		levelStructure := source.Structure()
+		levelWin := scaleWindow(win, float64(levelStructure.SizeX)/float64(structure.SizeX), float64(levelStructure.SizeY)/float64(structure.SizeY))
+		xRatio := levelWin.width() / float64(width)
+		yRatio := levelWin.height() / float64(height)
+
+		//only the output pixels whose centers fall on the raster are read, the others are NaN and end up transparent
+		data[i] = make([]float64, width*height)
+		for j := range data[i] {
+			data[i][j] = math.NaN()
+		}
+
+		outX0, outX1 := coveredPixels(levelWin.x0, xRatio, int(width), levelStructure.SizeX)
+		outY0, outY1 := coveredPixels(levelWin.y0, yRatio, int(height), levelStructure.SizeY)
+		if outX0 >= outX1 || outY0 >= outY1 {
+			continue
+		}
+
+		covered := window{
+			levelWin.x0 + float64(outX0)*xRatio, levelWin.y0 + float64(outY0)*yRatio,
+			levelWin.x0 + float64(outX1)*xRatio, levelWin.y0 + float64(outY1)*yRatio,
+		}
+
+		//the pixels around the window are read too, so that the resampling kernels have neighbours at the window's edges
+		marginX := int(math.Ceil(3 * math.Max(1, xRatio)))
+		marginY := int(math.Ceil(3 * math.Max(1, yRatio)))
+		x0 := clampIndex(int(math.Floor(covered.x0))-marginX, levelStructure.SizeX)
+		y0 := clampIndex(int(math.Floor(covered.y0))-marginY, levelStructure.SizeY)
+		x1 := clampIndex(int(math.Ceil(covered.x1))+marginX, levelStructure.SizeX+1)
+		y1 := clampIndex(int(math.Ceil(covered.y1))+marginY, levelStructure.SizeY+1)
+
+		buffer := make([]float64, (x1-x0)*(y1-y0))
+		td.lock.RLock()
+		err := source.Read(x0, y0, buffer, x1-x0, y1-y0)
+		td.lock.RUnlock()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		//NoData pixels become NaN, which the resampling ignores
+		td.noDataToNaN(band-1, buffer)
+
+		relative := window{covered.x0 - float64(x0), covered.y0 - float64(y0), covered.x1 - float64(x0), covered.y1 - float64(y0)}
+		coveredWidth := outX1 - outX0
+		resampled := resampleWindow(buffer, x1-x0, y1-y0, relative, coveredWidth, outY1-outY0, ro.resampling)
+		for y := outY0; y < outY1; y++ {
+			row := y*int(width) + outX0
+			copy(data[i][row:row+coveredWidth], resampled[(y-outY0)*coveredWidth:])
+		}
+	}
+
+	//like the alpha band of a warp, a pixel is covered when any band has a value for it
+	coverage := make([]float64, width*height)
+	for j := range coverage {
+		for _, band := range data {
+			if !math.IsNaN(band[j]) {
+				coverage[j] = 255
+				break
+			}
+		}
+	}
+
+	return data, coverage, nil
+}
+
+// bandNoData returns the NoData value of the band at index i, the one of WithNoData when it is overridden.
+func (td *TifDriver) bandNoData(i int) (float64, bool) {
+	if td.noData != nil {
+		return *td.noData, true
+	}
+
+	return td.dataset.Bands()[i].NoData()
+}
+
+// noDataToNaN replaces the NoData values of data, read from the band at index i, with NaN.
+func (td *TifDriver) noDataToNaN(i int, data []float64) {
+	if noData, ok := td.bandNoData(i); ok {
+		for j, v := range data {
+			if v == noData {
+				data[j] = math.NaN()
+			}
+		}
+	}
+}
+
+// pixelWindow returns the pixel coordinates of the bbox in a raster with the geotransform gt.
+// For rotated or sheared rasters, it is the smallest window containing the corners of the bbox.
+// It returns false when gt cannot be inverted.
+func pixelWindow(gt geoTransform, bbox [4]float64) (window, bool) {
+	inverse, ok := gt.invert()
+	if !ok {
+		return window{}, false
+	}
+
+	bounds := transformBounds(inverse, bbox)
+	return window{bounds[0], bounds[1], bounds[2], bounds[3]}, true
+}
+
+// coveredPixels returns the range [start, end) of the size output pixels whose centers fall inside [0, limit) of the source,
+// for output pixels of ratio source pixels starting at the source coordinate origin.
+func coveredPixels(origin, ratio float64, size, limit int) (start, end int) {
+	start = int(math.Ceil(-origin/ratio - 0.5))
+	end = int(math.Ceil((float64(limit)-origin)/ratio - 0.5))
+
+	return clampIndex(start, size+1), clampIndex(end, size+1)
+}
+
+func scaleWindow(win window, scaleX, scaleY float64) window {
+	return window{win.x0 * scaleX, win.y0 * scaleY, win.x1 * scaleX, win.y1 * scaleY}
+}
+
+// overviewFor returns the index of the coarsest overview of band whose pixels are at most ratio pixels of the band wide,
+// or -1 when no overview is coarse enough and the band itself has to be read.
+func overviewFor(band godal.Band, ratio float64) int {
+	sizeX := float64(band.Structure().SizeX)
+	best, bestFactor := -1, 1.0
+	for i, overview := range band.Overviews() {
+		//a small tolerance, since overview sizes are rounded
+		factor := sizeX / float64(overview.Structure().SizeX)
+		if factor <= ratio*1.01 && factor > bestFactor {
+			best, bestFactor = i, factor
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,134 @@
+package raster
+
+import (
+	"github.com/airbusgeo/godal"
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestPixelWindow(t *testing.T) {
+	gt := geoTransform{100, 10, 0, 500, 0, -10}
+	win, ok := pixelWindow(gt, [4]float64{100, 300, 200, 500})
+	assert.Assert(t, ok)
+	assert.Equal(t, win, window{0, 0, 10, 20})
+
+	win, ok = pixelWindow(gt, [4]float64{125, 445, 150, 475})
+	assert.Assert(t, ok)
+	assert.Equal(t, win, window{2.5, 2.5, 5, 5.5})
+
+	t.Run("ROTATED", func(t *testing.T) {
+		//rotated by 90 degrees: columns run north and rows run east
+		win, ok := pixelWindow(geoTransform{0, 0, 10, 0, 10, 0}, [4]float64{10, 20, 30, 60})
+		assert.Assert(t, ok)
+		assert.Equal(t, win, window{2, 1, 6, 3})
+	})
+
+	t.Run("NOT INVERTIBLE", func(t *testing.T) {
+		_, ok := pixelWindow(geoTransform{0, 10, 10, 0, 10, 10}, [4]float64{0, 0, 10, 10})
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestResampleWindow(t *testing.T) {
+	src := []float64{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+	}
+
+	t.Run("ALIGNED", func(t *testing.T) {
+		assert.DeepEqual(t, resampleWindow(src, 4, 2, window{1, 0, 3, 2}, 2, 2, ResampleNearest), []float64{2, 3, 6, 7})
+		assert.DeepEqual(t, resampleWindow(src, 4, 2, window{2, 0, 4, 2}, 1, 1, ResampleAverage), []float64{5.5})
+	})
+
+	t.Run("FRACTIONAL", func(t *testing.T) {
+		//the centers of the output pixels fall at x = 1.75 and 2.25
+		assert.DeepEqual(t, resampleWindow(src, 4, 2, window{1.5, 0, 2.5, 1}, 2, 1, ResampleNearest), []float64{2, 3})
+		out := resampleWindow(src, 4, 2, window{1.5, 0, 2.5, 1}, 2, 1, ResampleBilinear)
+		assert.Assert(t, math.Abs(out[0]-2.25) < 1e-9 && math.Abs(out[1]-2.75) < 1e-9, "%v", out)
+	})
+}
+
+func TestReadWindow(t *testing.T) {
+	const width, height = 16, 16
+	path := createTestRaster(t, "windowed.tif", godal.Float32, width, height, ramp(width*height, 0, 1))
+	driver, err := Load(path)
+	assert.NilError(t, err)
+	td := driver.(*TifDriver)
+
+	t.Run("SAME AS WARP", func(t *testing.T) {
+		ro := newRenderOptions()
+		assert.Check(t, td.sameSRS(ro))
+
+		//a bbox aligned with the pixels of the raster, at half its resolution
+		bbox := [4]float64{40, 40, 120, 120}
+		windowed, coverage, ok, err := td.readWindow(bbox, 4, 4, ro, 1)
+		assert.NilError(t, err)
+		assert.Assert(t, ok)
+
+		warped, err := td.warp(bbox, 4, 4, ro, 1)
+		assert.NilError(t, err)
+		defer warped.Close()
+		data, warpedCoverage, err := td.read(warped, 4, 4)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, windowed[0], data[0])
+		assert.DeepEqual(t, coverage, warpedCoverage)
+	})
+
+	t.Run("OTHER SRS", func(t *testing.T) {
+		assert.Check(t, !td.sameSRS(newRenderOptions(WithSRS("EPSG:4326"))))
+	})
+}
+
+func TestCoveredPixels(t *testing.T) {
+	start, end := coveredPixels(0, 1, 4, 4)
+	assert.Equal(t, [2]int{start, end}, [2]int{0, 4})
+
+	//the window starts 2 pixels left of the raster
+	start, end = coveredPixels(-2, 1, 4, 4)
+	assert.Equal(t, [2]int{start, end}, [2]int{2, 4})
+
+	//the window ends 1.5 pixels right of the raster, with output pixels of 0.5 pixels
+	start, end = coveredPixels(2, 0.5, 7, 4)
+	assert.Equal(t, [2]int{start, end}, [2]int{0, 4})
+
+	start, end = coveredPixels(10, 1, 4, 4)
+	assert.Equal(t, start >= end, true)
+}
+
+func benchmarkRender(b *testing.B, read func(td *TifDriver, bbox [4]float64, ro *renderOptions) error) {
+	const width, height = 2048, 2048
+	path := createTestRaster(b, "benchmark.tif", godal.Float32, width, height, ramp(width*height, 0, 1))
+	driver, err := Load(path, WithOverviews())
+	assert.NilError(b, err)
+	td := driver.(*TifDriver)
+
+	ro := newRenderOptions(WithResampling(ResampleBilinear))
+	bbox := [4]float64{0, 0, width * testPixelSize / 2, height * testPixelSize / 2}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		assert.NilError(b, read(td, bbox, ro))
+	}
+}
+
+func BenchmarkReadWindow(b *testing.B) {
+	benchmarkRender(b, func(td *TifDriver, bbox [4]float64, ro *renderOptions) error {
+		_, _, _, err := td.readWindow(bbox, 256, 256, ro, 1)
+		return err
+	})
+}
+
+func BenchmarkWarp(b *testing.B) {
+	benchmarkRender(b, func(td *TifDriver, bbox [4]float64, ro *renderOptions) error {
+		warped, err := td.warp(bbox, 256, 256, ro, 1)
+		if err != nil {
+			return err
+		}
+		defer warped.Close()
+
+		_, _, err = td.read(warped, 256, 256)
+		return err
+	})
+}
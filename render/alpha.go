@@ -0,0 +1,43 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// AlphaDrawer scales the opacity of every pixel drawn by another Drawer with the values of an alpha band.
+type AlphaDrawer struct {
+	width  int
+	height int
+
+	drawer Drawer
+	alpha  Channel
+}
+
+func NewAlphaDrawer(drawer Drawer, alpha Channel, width, height int) Drawer {
+	return &AlphaDrawer{
+		width:  width,
+		height: height,
+		drawer: drawer,
+		alpha:  alpha,
+	}
+}
+
+func (ad *AlphaDrawer) Draw() (image.Image, error) {
+	src, err := ad.drawer.Draw()
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, ad.width, ad.height))
+	for y := 0; y < ad.height; y++ {
+		for x := 0; x < ad.width; x++ {
+			c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			alpha := normalizeByte(ad.alpha.Data[y*ad.width+x], ad.alpha.Min, ad.alpha.Max)
+			c.A = uint8(uint16(c.A) * uint16(alpha) / 255)
+			//Set premultiplies the non-premultiplied color
+			img.Set(x, y, c)
+		}
+	}
+	return img, nil
+}
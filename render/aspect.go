@@ -0,0 +1,50 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// AspectDrawer renders the compass direction the slopes of an elevation model face, downhill, in degrees clockwise
+// from north: 0 faces north, 90 east, 180 south and 270 west.
+// Without a TerrainStyle, north is black and the gray levels grow clockwise up to white.
+// Flat areas have no aspect and are NaN like NoData pixels, use an AlphaDrawer to make them transparent.
+type AspectDrawer struct {
+	elevation Elevation
+	options   terrainOptions
+
+	aspect []float64
+}
+
+func NewAspectDrawer(elevation Elevation, options ...TerrainOption) *AspectDrawer {
+	ad := &AspectDrawer{elevation: elevation}
+	for _, option := range options {
+		option(&ad.options)
+	}
+
+	return ad
+}
+
+func (ad *AspectDrawer) Draw() (image.Image, error) {
+	return ad.options.draw(ad.Aspect(), ad.elevation.Width, ad.elevation.Height, 360)
+}
+
+// Aspect returns the direction every pixel faces, from 0 up to 360, NaN for flat and NoData pixels. It is computed once.
+func (ad *AspectDrawer) Aspect() []float64 {
+	if ad.aspect == nil {
+		ad.aspect = ad.elevation.terrain(func(east, north float64) float64 {
+			if east == 0 && north == 0 {
+				return math.NaN()
+			}
+
+			//downhill is the opposite of the gradient
+			aspect := math.Atan2(-east, -north) * 180 / math.Pi
+			if aspect < 0 {
+				aspect += 360
+			}
+			return aspect
+		})
+	}
+
+	return ad.aspect
+}
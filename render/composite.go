@@ -0,0 +1,50 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// Channel is the data of a single band used as one of the red, green or blue components of an image.
+// The data is stretched linearly from [Min, Max] to 0-255, so 8-bit data should use a range of [0, 255].
+type Channel struct {
+	Data []float64
+	Min  float64
+	Max  float64
+}
+
+type CompositeDrawer struct {
+	width  int
+	height int
+
+	red   Channel
+	green Channel
+	blue  Channel
+}
+
+func NewCompositeDrawer(red, green, blue Channel, width, height int) Drawer {
+	return &CompositeDrawer{
+		width:  width,
+		height: height,
+		red:    red,
+		green:  green,
+		blue:   blue,
+	}
+}
+
+func (cd *CompositeDrawer) Draw() (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, cd.width, cd.height))
+
+	for y := 0; y < cd.height; y++ {
+		for x := 0; x < cd.width; x++ {
+			i := y*cd.width + x
+			img.SetRGBA(x, y, color.RGBA{
+				R: normalizeByte(cd.red.Data[i], cd.red.Min, cd.red.Max),
+				G: normalizeByte(cd.green.Data[i], cd.green.Min, cd.green.Max),
+				B: normalizeByte(cd.blue.Data[i], cd.blue.Min, cd.blue.Max),
+				A: 255,
+			})
+		}
+	}
+	return img, nil
+}
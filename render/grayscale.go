@@ -3,6 +3,7 @@ package render
 import (
 	"image"
 	"image/color"
+	"math"
 )
 
 type GrayscaleRenderer struct {
@@ -11,17 +12,21 @@ type GrayscaleRenderer struct {
 
 	data []float64
 
-	min float64
-	max float64
+	stretch Stretch
 }
 
+// Grayscale linearly stretches data from min (black) to max (white).
 func Grayscale(data []float64, width, height int, min, max float64) Drawer {
+	return StretchedGrayscale(data, width, height, LinearStretch(min, max))
+}
+
+// StretchedGrayscale maps data to gray levels with the given stretch.
+func StretchedGrayscale(data []float64, width, height int, stretch Stretch) Drawer {
 	return &GrayscaleRenderer{
-		width:  width,
-		height: height,
-		data:   data,
-		min:    min,
-		max:    max,
+		width:   width,
+		height:  height,
+		data:    data,
+		stretch: stretch,
 	}
 }
 
@@ -31,7 +36,7 @@ func (gr *GrayscaleRenderer) Draw() (image.Image, error) {
 	// Normalize and apply the color map
 	for y := 0; y < gr.height; y++ {
 		for x := 0; x < gr.width; x++ {
-			img.SetGray(x, y, color.Gray{Y: normalizeByte(gr.data[y*gr.width+x], gr.min, gr.max)})
+			img.SetGray(x, y, color.Gray{Y: uint8(math.Round(gr.stretch(gr.data[y*gr.width+x]) * 255))})
 		}
 	}
 	return img, nil
@@ -40,8 +45,16 @@ func (gr *GrayscaleRenderer) Draw() (image.Image, error) {
 //
 
 func normalizeByte(value, min, max float64) uint8 {
-	if max == min {
+	if max == min || math.IsNaN(value) {
+		return 0
+	}
+
+	scaled := math.Round(((value - min) / (max - min)) * 255)
+	if scaled < 0 {
 		return 0
 	}
-	return uint8(((value - min) / (max - min)) * 255)
+	if scaled > 255 {
+		return 255
+	}
+	return uint8(scaled)
 }
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// multidirectionalAzimuths are the directions combined by multidirectional hillshades, like gdaldem -multidirectional
+var multidirectionalAzimuths = []float64{225, 270, 315, 360}
+
+// HillshadeDrawer renders an elevation model as grayscale, lit by the sun.
+// NoData pixels are black, use an AlphaDrawer to make them transparent.
+type HillshadeDrawer struct {
+	elevation Elevation
+
+	azimuth          float64
+	altitude         float64
+	multidirectional bool
+}
+
+// NewHillshadeDrawer lights the elevation from the north-west (315 degrees), 45 degrees above the horizon, unless set otherwise.
+func NewHillshadeDrawer(elevation Elevation, options ...HillshadeOption) *HillshadeDrawer {
+	hd := &HillshadeDrawer{
+		elevation: elevation,
+		azimuth:   315,
+		altitude:  45,
+	}
+
+	for _, option := range options {
+		option(hd)
+	}
+
+	return hd
+}
+
+type HillshadeOption func(*HillshadeDrawer)
+
+// Azimuth sets the direction the light comes from, in degrees clockwise from north.
+func Azimuth(azimuth float64) HillshadeOption {
+	return func(hd *HillshadeDrawer) {
+		hd.azimuth = azimuth
+	}
+}
+
+// Altitude sets the angle of the light above the horizon, in degrees.
+func Altitude(altitude float64) HillshadeOption {
+	return func(hd *HillshadeDrawer) {
+		hd.altitude = altitude
+	}
+}
+
+// Multidirectional combines the light coming from 225, 270, 315 and 360 degrees, weighting each direction by how much it
+// grazes the slope, instead of lighting from a single azimuth. It shows more relief in areas facing a single light.
+func Multidirectional() HillshadeOption {
+	return func(hd *HillshadeDrawer) {
+		hd.multidirectional = true
+	}
+}
+
+func (hd *HillshadeDrawer) Draw() (image.Image, error) {
+	shade := hd.Shade()
+
+	img := image.NewGray(image.Rect(0, 0, hd.elevation.Width, hd.elevation.Height))
+	for i, v := range shade {
+		if !math.IsNaN(v) {
+			img.Pix[i] = uint8(math.Round(v * 255))
+		}
+	}
+	return img, nil
+}
+
+// Shade returns the brightness of every pixel, from 0 (in the shadow) to 1 (facing the light), NaN for NoData pixels.
+func (hd *HillshadeDrawer) Shade() []float64 {
+	altitude := hd.altitude * math.Pi / 180
+	return hd.elevation.terrain(func(east, north float64) float64 {
+		if !hd.multidirectional {
+			return illumination(east, north, hd.azimuth*math.Pi/180, altitude)
+		}
+
+		//the compass direction the slope faces, downhill
+		aspect := math.Atan2(-east, -north)
+
+		//the weights always add up to 2
+		var shade float64
+		for _, azimuth := range multidirectionalAzimuths {
+			azimuth *= math.Pi / 180
+			weight := math.Pow(math.Sin(aspect-azimuth), 2)
+			shade += weight * illumination(east, north, azimuth, altitude)
+		}
+		return shade / 2
+	})
+}
+
+// illumination returns the cosine of the angle between the normal of a surface rising east and north at the given rates
+// and the direction of the light, both angles in radians. Surfaces facing away from the light are 0.
+func illumination(east, north, azimuth, altitude float64) float64 {
+	light := math.Sin(altitude) - math.Cos(altitude)*(north*math.Cos(azimuth)+east*math.Sin(azimuth))
+	return math.Max(0, light/math.Sqrt(1+east*east+north*north))
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"gotest.tools/v3/assert"
+	"image"
+	"math"
+	"testing"
+)
+
+// plane returns the elevation of a (width+2) x (height+2) plane rising by east and north units per pixel.
+func plane(width, height int, east, north float64) Elevation {
+	data := make([]float64, (width+2)*(height+2))
+	for y := 0; y < height+2; y++ {
+		for x := 0; x < width+2; x++ {
+			data[y*(width+2)+x] = float64(x)*east - float64(y)*north
+		}
+	}
+
+	return Elevation{Data: data, Width: width, Height: height, CellSizeX: 1, CellSizeY: 1}
+}
+
+func TestHillshade(t *testing.T) {
+	t.Run("FLAT", func(t *testing.T) {
+		for _, options := range [][]HillshadeOption{nil, {Multidirectional()}} {
+			shade := NewHillshadeDrawer(plane(2, 2, 0, 0), options...).Shade()
+			for _, v := range shade {
+				assert.Assert(t, math.Abs(v-math.Sin(math.Pi/4)) < 1e-9, "%v", shade)
+			}
+		}
+	})
+
+	t.Run("SLOPE", func(t *testing.T) {
+		//a 45 degrees slope rising towards the east, lit from the north-west
+		shade := NewHillshadeDrawer(plane(1, 1, 1, 0)).Shade()
+		assert.Assert(t, math.Abs(shade[0]-(math.Sqrt(0.5)+0.5)/math.Sqrt(2)) < 1e-9, "%v", shade)
+
+		//facing the light is brighter than facing away from it
+		towards := NewHillshadeDrawer(plane(1, 1, 1, -1)).Shade()[0]
+		away := NewHillshadeDrawer(plane(1, 1, -1, 1)).Shade()[0]
+		assert.Assert(t, towards > shade[0] && shade[0] > away, "%f %f %f", towards, shade[0], away)
+
+		//lit from the east, the steep slope facing west is in the shadow
+		assert.Equal(t, NewHillshadeDrawer(plane(1, 1, 2, 0), Azimuth(90), Altitude(10)).Shade()[0], 0.0)
+	})
+
+	t.Run("Z FACTOR AND SCALE", func(t *testing.T) {
+		exaggerated := plane(1, 1, 0.5, 0)
+		exaggerated.ZFactor = 2
+		assert.Equal(t, NewHillshadeDrawer(exaggerated).Shade()[0], NewHillshadeDrawer(plane(1, 1, 1, 0)).Shade()[0])
+
+		scaled := plane(1, 1, 2, 0)
+		scaled.Scale = 2
+		assert.Equal(t, NewHillshadeDrawer(scaled).Shade()[0], NewHillshadeDrawer(plane(1, 1, 1, 0)).Shade()[0])
+	})
+
+	t.Run("NODATA", func(t *testing.T) {
+		elevation := plane(2, 1, 1, 0)
+		//the north-west neighbour of the first pixel and the second pixel itself
+		elevation.Data[0] = math.NaN()
+		elevation.Data[4+2] = math.NaN()
+
+		shade := NewHillshadeDrawer(elevation).Shade()
+		assert.Assert(t, !math.IsNaN(shade[0]))
+		assert.Assert(t, math.IsNaN(shade[1]))
+
+		img, err := NewHillshadeDrawer(elevation).Draw()
+		assert.NilError(t, err)
+		assert.Equal(t, img.(*image.Gray).Pix[1], uint8(0))
+	})
+}
@@ -0,0 +1,91 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/models"
+	"image"
+	"math"
+)
+
+// ReliefDrawer colors an elevation model through the color map of a style, a hypsometric tint,
+// and blends the hillshade of the elevations on top of it.
+// NoData pixels and the elevations the color map does not paint are transparent.
+type ReliefDrawer struct {
+	hillshade *HillshadeDrawer
+	style     models.RasterStyle
+
+	blend    models.BlendMode
+	strength float64
+}
+
+// NewReliefDrawer fully multiplies the colors by the shade of the hillshade, unless set otherwise.
+func NewReliefDrawer(hillshade *HillshadeDrawer, style models.RasterStyle, options ...ReliefOption) *ReliefDrawer {
+	rd := &ReliefDrawer{
+		hillshade: hillshade,
+		style:     style,
+		blend:     models.BlendMultiply,
+		strength:  1,
+	}
+
+	for _, option := range options {
+		option(rd)
+	}
+
+	return rd
+}
+
+type ReliefOption func(*ReliefDrawer)
+
+// Blend sets how the hillshade is blended with the colors.
+func Blend(blend models.BlendMode) ReliefOption {
+	return func(rd *ReliefDrawer) {
+		rd.blend = blend
+	}
+}
+
+// Strength sets how much the hillshade shows, from 0 (the colors alone) to 1 (fully blended).
+func Strength(strength float64) ReliefOption {
+	return func(rd *ReliefDrawer) {
+		rd.strength = strength
+	}
+}
+
+func (rd *ReliefDrawer) Draw() (image.Image, error) {
+	elevation := rd.hillshade.elevation
+	shade := rd.hillshade.Shade()
+
+	img := image.NewNRGBA(image.Rect(0, 0, elevation.Width, elevation.Height))
+	for y := 0; y < elevation.Height; y++ {
+		for x := 0; x < elevation.Width; x++ {
+			i := y*elevation.Width + x
+			c := rd.style.GetColor(elevation.Data[(y+1)*(elevation.Width+2)+x+1])
+			if c.A == 0 || math.IsNaN(shade[i]) {
+				continue
+			}
+
+			img.Pix[i*4] = rd.channel(c.R, shade[i])
+			img.Pix[i*4+1] = rd.channel(c.G, shade[i])
+			img.Pix[i*4+2] = rd.channel(c.B, shade[i])
+			img.Pix[i*4+3] = c.A
+		}
+	}
+	return img, nil
+}
+
+// channel blends a color channel with the shade, from 0 to 1.
+func (rd *ReliefDrawer) channel(value uint8, shade float64) uint8 {
+	c := float64(value) / 255
+
+	var blended float64
+	switch rd.blend {
+	case models.BlendOverlay:
+		if c < 0.5 {
+			blended = 2 * c * shade
+		} else {
+			blended = 1 - 2*(1-c)*(1-shade)
+		}
+	default:
+		blended = c * shade
+	}
+
+	return uint8(math.Round((c + rd.strength*(blended-c)) * 255))
+}
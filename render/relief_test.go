@@ -0,0 +1,54 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestRelief(t *testing.T) {
+	style := models.RasterStyle{
+		ColorMap: []models.ColorMapEntry{
+			{Color: "#CC8040", Quantity: 100, Opacity: 1},
+		},
+	}
+
+	//lit from straight above, flat areas are fully lit
+	flat := NewHillshadeDrawer(plane(2, 1, 0, 0), Altitude(90))
+
+	//a 45 degrees slope rising towards the east, lit from the west, is in the shadow
+	shadow := NewHillshadeDrawer(plane(1, 1, 1, 0), Azimuth(90), Altitude(45))
+
+	at := func(t *testing.T, drawer *ReliefDrawer) color.NRGBA {
+		img, err := drawer.Draw()
+		assert.NilError(t, err)
+		return img.(*image.NRGBA).NRGBAAt(0, 0)
+	}
+
+	t.Run("MULTIPLY", func(t *testing.T) {
+		assert.Equal(t, at(t, NewReliefDrawer(flat, style)), color.NRGBA{R: 0xCC, G: 0x80, B: 0x40, A: 255})
+		assert.Equal(t, at(t, NewReliefDrawer(shadow, style)), color.NRGBA{A: 255})
+	})
+
+	t.Run("OVERLAY", func(t *testing.T) {
+		//fully lit, the colors get lighter
+		c := at(t, NewReliefDrawer(flat, style, Blend(models.BlendOverlay)))
+		assert.Equal(t, c, color.NRGBA{R: 255, G: 255, B: 0x80, A: 255})
+	})
+
+	t.Run("STRENGTH", func(t *testing.T) {
+		assert.Equal(t, at(t, NewReliefDrawer(shadow, style, Strength(0))), color.NRGBA{R: 0xCC, G: 0x80, B: 0x40, A: 255})
+		assert.Equal(t, at(t, NewReliefDrawer(shadow, style, Strength(0.5))), color.NRGBA{R: 0x66, G: 0x40, B: 0x20, A: 255})
+	})
+
+	t.Run("NODATA", func(t *testing.T) {
+		elevation := plane(2, 1, 0, 0)
+		elevation.Data[4+2] = math.NaN()
+		img, err := NewReliefDrawer(NewHillshadeDrawer(elevation), style).Draw()
+		assert.NilError(t, err)
+		assert.Equal(t, img.(*image.NRGBA).NRGBAAt(1, 0), color.NRGBA{})
+	})
+}
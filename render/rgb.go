@@ -2,7 +2,6 @@ package render
 
 import (
 	"github.com/canghel3/raster2image/models"
-	"github.com/canghel3/raster2image/utils"
 	"image"
 	"image/color"
 )
@@ -45,26 +44,13 @@ func (rr *RGBDrawer) Draw() (image.Image, error) {
 	for y := 0; y < rr.height; y++ {
 		for x := 0; x < rr.width; x++ {
 			value := rr.data[y*rr.width+x]
+			//the color is not premultiplied, Set takes care of it
 			img.Set(x, y, rr.getColor(value))
 		}
 	}
 	return img, nil
 }
 
-func (rr *RGBDrawer) getColor(value float64) color.RGBA {
-	for i, entry := range rr.styling.ColorMap {
-		if i == 0 {
-			if value <= entry.Quantity {
-				return utils.HexToRGBA(entry.Color)
-			}
-
-			continue
-		}
-
-		if rr.styling.ColorMap[i-1].Quantity < value && value <= entry.Quantity {
-			return utils.HexToRGBA(entry.Color)
-		}
-	}
-
-	return utils.HexToRGBA(rr.styling.ColorMap[len(rr.styling.ColorMap)-1].Color)
+func (rr *RGBDrawer) getColor(value float64) color.NRGBA {
+	return rr.styling.GetColor(value)
 }
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image/color"
+	"testing"
+)
+
+func TestRGBDrawerOpacity(t *testing.T) {
+	style := models.RasterStyle{
+		ColorMap: []models.ColorMapEntry{
+			{Color: "#ff0000", Quantity: 10, Opacity: 0.5},
+			{Color: "#00ff00", Quantity: 20, Opacity: 1},
+			{Color: "#0000ff80", Quantity: 30, Opacity: 0.5},
+		},
+	}
+
+	img, err := NewRGBDrawer([]float64{5, 15, 25}, 3, 1, StyleOption(style)).Draw()
+	assert.NilError(t, err)
+
+	//the image holds premultiplied colors
+	assert.Equal(t, img.At(0, 0), color.RGBA{R: 128, A: 128})
+	assert.Equal(t, img.At(1, 0), color.RGBA{G: 255, A: 255})
+	assert.Equal(t, img.At(2, 0), color.RGBA{B: 64, A: 64})
+
+	assert.Equal(t, color.NRGBAModel.Convert(img.At(0, 0)), color.NRGBA{R: 255, A: 128})
+}
+
+func TestRGBDrawerColorMapTypes(t *testing.T) {
+	colorMap := []models.ColorMapEntry{
+		{Color: "#ff0000", Quantity: 0, Opacity: 0},
+		{Color: "#ff0000", Quantity: 10, Opacity: 1},
+		{Color: "#ffffff", Quantity: 20, Opacity: 1},
+	}
+	data := []float64{-5, 0, 5, 10, 15, 20, 25}
+
+	t.Run("RAMP", func(t *testing.T) {
+		style := models.RasterStyle{ColorMapType: models.ColorMapRamp, ColorMap: colorMap}
+		img, err := NewRGBDrawer(data, len(data), 1, StyleOption(style)).Draw()
+		assert.NilError(t, err)
+
+		expected := []color.NRGBA{
+			{},
+			{},
+			{R: 255, A: 128},
+			{R: 255, A: 255},
+			{R: 255, G: 128, B: 128, A: 255},
+			{R: 255, G: 255, B: 255, A: 255},
+			{R: 255, G: 255, B: 255, A: 255},
+		}
+		for x, c := range expected {
+			assert.Equal(t, color.NRGBAModel.Convert(img.At(x, 0)), c, "pixel %d", x)
+		}
+	})
+
+	t.Run("INTERVALS", func(t *testing.T) {
+		style := models.RasterStyle{ColorMapType: models.ColorMapIntervals, ColorMap: colorMap}
+		img, err := NewRGBDrawer(data, len(data), 1, StyleOption(style)).Draw()
+		assert.NilError(t, err)
+
+		expected := []color.NRGBA{
+			{},
+			{},
+			{R: 255, A: 255},
+			{R: 255, A: 255},
+			{R: 255, G: 255, B: 255, A: 255},
+			{R: 255, G: 255, B: 255, A: 255},
+			{R: 255, G: 255, B: 255, A: 255},
+		}
+		for x, c := range expected {
+			assert.Equal(t, color.NRGBAModel.Convert(img.At(x, 0)), c, "pixel %d", x)
+		}
+	})
+
+	t.Run("VALUES", func(t *testing.T) {
+		style := models.RasterStyle{ColorMapType: models.ColorMapValues, ColorMap: colorMap}
+		img, err := NewRGBDrawer(data, len(data), 1, StyleOption(style)).Draw()
+		assert.NilError(t, err)
+
+		expected := []color.NRGBA{
+			{},
+			{},
+			{},
+			{R: 255, A: 255},
+			{},
+			{R: 255, G: 255, B: 255, A: 255},
+			{},
+		}
+		for x, c := range expected {
+			assert.Equal(t, color.NRGBAModel.Convert(img.At(x, 0)), c, "pixel %d", x)
+		}
+	})
+}
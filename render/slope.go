@@ -0,0 +1,50 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// SlopeDrawer renders the steepness of an elevation model, in degrees from 0 to 90 or in percent.
+// Without a TerrainStyle, flat areas are black and slopes of 90 degrees or 100 percent and more are white.
+// NoData pixels are black without a TerrainStyle, use an AlphaDrawer to make them transparent.
+type SlopeDrawer struct {
+	elevation Elevation
+	options   terrainOptions
+
+	slope []float64
+}
+
+// NewSlopeDrawer measures the slopes of the elevation in degrees, unless Percent is given.
+func NewSlopeDrawer(elevation Elevation, options ...TerrainOption) *SlopeDrawer {
+	sd := &SlopeDrawer{elevation: elevation}
+	for _, option := range options {
+		option(&sd.options)
+	}
+
+	return sd
+}
+
+func (sd *SlopeDrawer) Draw() (image.Image, error) {
+	max := 90.0
+	if sd.options.percent {
+		max = 100
+	}
+
+	return sd.options.draw(sd.Slope(), sd.elevation.Width, sd.elevation.Height, max)
+}
+
+// Slope returns the steepness of every pixel, NaN for NoData pixels. It is computed once.
+func (sd *SlopeDrawer) Slope() []float64 {
+	if sd.slope == nil {
+		sd.slope = sd.elevation.terrain(func(east, north float64) float64 {
+			rise := math.Hypot(east, north)
+			if sd.options.percent {
+				return rise * 100
+			}
+			return math.Atan(rise) * 180 / math.Pi
+		})
+	}
+
+	return sd.slope
+}
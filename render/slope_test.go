@@ -0,0 +1,80 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/models"
+	"gotest.tools/v3/assert"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestSlope(t *testing.T) {
+	t.Run("DEGREES", func(t *testing.T) {
+		assert.DeepEqual(t, NewSlopeDrawer(plane(2, 1, 0, 0)).Slope(), []float64{0, 0})
+		assert.Assert(t, math.Abs(NewSlopeDrawer(plane(1, 1, 1, 0)).Slope()[0]-45) < 1e-9)
+
+		//the steepest direction is diagonal
+		slope := NewSlopeDrawer(plane(1, 1, 1, 1)).Slope()[0]
+		assert.Assert(t, math.Abs(slope-math.Atan(math.Sqrt2)*180/math.Pi) < 1e-9, "%f", slope)
+	})
+
+	t.Run("PERCENT", func(t *testing.T) {
+		assert.Assert(t, math.Abs(NewSlopeDrawer(plane(1, 1, 0, 0.5), Percent()).Slope()[0]-50) < 1e-9)
+	})
+
+	t.Run("CELL SIZE", func(t *testing.T) {
+		//rising by 1 every 2 units
+		elevation := plane(1, 1, 1, 0)
+		elevation.CellSizeX = 2
+		assert.Assert(t, math.Abs(NewSlopeDrawer(elevation, Percent()).Slope()[0]-50) < 1e-9)
+	})
+
+	t.Run("NODATA", func(t *testing.T) {
+		elevation := plane(2, 1, 1, 0)
+		elevation.Data[4+2] = math.NaN()
+		slope := NewSlopeDrawer(elevation).Slope()
+		assert.Assert(t, math.IsNaN(slope[1]))
+	})
+
+	t.Run("DRAW", func(t *testing.T) {
+		img, err := NewSlopeDrawer(plane(1, 1, 1, 0)).Draw()
+		assert.NilError(t, err)
+		assert.Equal(t, img.(*image.Gray).Pix[0], uint8(128))
+
+		//classified through the color map
+		style := models.RasterStyle{
+			ColorMap: []models.ColorMapEntry{
+				{Color: "#00FF00", Quantity: 30, Opacity: 1},
+				{Color: "#FF0000", Quantity: 90, Opacity: 1},
+			},
+		}
+		img, err = NewSlopeDrawer(plane(2, 1, 0.1, 0), TerrainStyle(style)).Draw()
+		assert.NilError(t, err)
+		assert.Equal(t, color.NRGBAModel.Convert(img.At(0, 0)), color.Color(color.NRGBA{G: 255, A: 255}))
+
+		img, err = NewSlopeDrawer(plane(2, 1, 10, 0), TerrainStyle(style)).Draw()
+		assert.NilError(t, err)
+		assert.Equal(t, color.NRGBAModel.Convert(img.At(0, 0)), color.Color(color.NRGBA{R: 255, A: 255}))
+	})
+}
+
+func TestAspect(t *testing.T) {
+	//the slopes face downhill, opposite to the direction they rise towards
+	for expected, elevation := range map[float64]Elevation{
+		0:   plane(1, 1, 0, -1),
+		90:  plane(1, 1, -1, 0),
+		180: plane(1, 1, 0, 1),
+		270: plane(1, 1, 1, 0),
+		225: plane(1, 1, 1, 1),
+	} {
+		aspect := NewAspectDrawer(elevation).Aspect()[0]
+		assert.Assert(t, math.Abs(aspect-expected) < 1e-9, "expected %f, got %f", expected, aspect)
+	}
+
+	assert.Assert(t, math.IsNaN(NewAspectDrawer(plane(1, 1, 0, 0)).Aspect()[0]))
+
+	img, err := NewAspectDrawer(plane(1, 1, 0, 1)).Draw()
+	assert.NilError(t, err)
+	assert.Equal(t, img.(*image.Gray).Pix[0], uint8(128))
+}
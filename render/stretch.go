@@ -0,0 +1,72 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/stats"
+	"math"
+)
+
+// Stretch maps a value to a gray level from 0 (black) to 1 (white). NaN values are black.
+type Stretch func(value float64) float64
+
+// LinearStretch maps min to black and max to white, values outside are clipped.
+func LinearStretch(min, max float64) Stretch {
+	return func(value float64) float64 {
+		if max == min || math.IsNaN(value) {
+			return 0
+		}
+
+		return math.Max(0, math.Min(1, (value-min)/(max-min)))
+	}
+}
+
+// EqualizeStretch maps values to the fraction of values of the histogram below them,
+// so that every gray level is about as frequent.
+func EqualizeStretch(histogram stats.Histogram) Stretch {
+	var total float64
+	cumulative := make([]float64, len(histogram.Counts)+1)
+	for i, count := range histogram.Counts {
+		total += float64(count)
+		cumulative[i+1] = total
+	}
+
+	width := (histogram.Max - histogram.Min) / float64(len(histogram.Counts))
+	return func(value float64) float64 {
+		if total == 0 || width == 0 || math.IsNaN(value) {
+			return 0
+		}
+
+		position := (value - histogram.Min) / width
+		if position <= 0 {
+			return 0
+		}
+		if position >= float64(len(histogram.Counts)) {
+			return 1
+		}
+
+		//values are assumed to be evenly spread in their bin
+		bin := int(position)
+		fraction := position - float64(bin)
+		return (cumulative[bin] + fraction*float64(histogram.Counts[bin])) / total
+	}
+}
+
+// Gamma raises the gray levels of the stretch to the power of 1/gamma, a gamma above 1 brightens the dark levels.
+func (s Stretch) Gamma(gamma float64) Stretch {
+	return func(value float64) float64 {
+		return math.Pow(s(value), 1/gamma)
+	}
+}
+
+// Log brightens the dark gray levels of the stretch logarithmically.
+func (s Stretch) Log() Stretch {
+	return func(value float64) float64 {
+		return math.Log10(1+99*s(value)) / 2
+	}
+}
+
+// Sqrt takes the square root of the gray levels of the stretch.
+func (s Stretch) Sqrt() Stretch {
+	return func(value float64) float64 {
+		return math.Sqrt(s(value))
+	}
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/stats"
+	"gotest.tools/v3/assert"
+	"image"
+	"math"
+	"testing"
+)
+
+func TestStretch(t *testing.T) {
+	t.Run("LINEAR", func(t *testing.T) {
+		stretch := LinearStretch(10, 20)
+		assert.Equal(t, stretch(15), 0.5)
+		assert.Equal(t, stretch(0), 0.0)
+		assert.Equal(t, stretch(30), 1.0)
+		assert.Equal(t, stretch(math.NaN()), 0.0)
+		assert.Equal(t, LinearStretch(10, 10)(10), 0.0)
+	})
+
+	t.Run("EQUALIZE", func(t *testing.T) {
+		//most values are in the first bin, so it takes most of the gray levels
+		stretch := EqualizeStretch(stats.Histogram{Min: 0, Max: 4, Counts: []int{6, 1, 1, 0}})
+		assert.Equal(t, stretch(0), 0.0)
+		assert.Equal(t, stretch(0.5), 0.375)
+		assert.Equal(t, stretch(1), 0.75)
+		assert.Equal(t, stretch(3), 1.0)
+		assert.Equal(t, stretch(5), 1.0)
+		assert.Equal(t, EqualizeStretch(stats.Histogram{Counts: []int{0}})(1), 0.0)
+	})
+
+	t.Run("CURVES", func(t *testing.T) {
+		stretch := LinearStretch(0, 100)
+		assert.Equal(t, stretch.Gamma(2)(25), 0.5)
+		assert.Equal(t, stretch.Sqrt()(25), 0.5)
+		assert.Equal(t, stretch.Log()(100), 1.0)
+		assert.Equal(t, stretch.Log()(0), 0.0)
+		//both brighten the dark values
+		assert.Assert(t, stretch.Log()(10) > stretch.Sqrt()(10) && stretch.Sqrt()(10) > stretch(10))
+	})
+}
+
+func TestStretchedGrayscale(t *testing.T) {
+	img, err := StretchedGrayscale([]float64{0, 25, 100, math.NaN()}, 2, 2, LinearStretch(0, 100).Sqrt()).Draw()
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, img.(*image.Gray).Pix, []uint8{0, 128, 255, 0})
+}
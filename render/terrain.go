@@ -0,0 +1,114 @@
+package render
+
+import (
+	"github.com/canghel3/raster2image/models"
+	"image"
+	"math"
+)
+
+// Elevation is an elevation model read with a one pixel border around the rendered area, so that the pixels on the edges
+// have all their neighbours and tiles rendered separately do not show seams.
+type Elevation struct {
+	// Data holds (Width+2) x (Height+2) elevations, row by row from north to south. NaN elevations are NoData.
+	Data []float64
+	// Width and Height are the size of the rendered area, without the border
+	Width  int
+	Height int
+	// CellSizeX and CellSizeY are the width and height of a pixel, in horizontal units
+	CellSizeX float64
+	CellSizeY float64
+	// ZFactor exaggerates the elevations, 1 if 0
+	ZFactor float64
+	// Scale is the number of vertical units in a horizontal unit, 1 if 0
+	Scale float64
+}
+
+// gradient returns the rate at which the elevation rises towards the east and the north at the pixel x, y of the rendered area,
+// with Horn's method like gdaldem. NoData neighbours count as the pixel itself. It returns false when the pixel is NoData.
+func (e Elevation) gradient(x, y int) (east, north float64, ok bool) {
+	stride := e.Width + 2
+	center := e.Data[(y+1)*stride+x+1]
+	if math.IsNaN(center) {
+		return 0, 0, false
+	}
+
+	//the 3x3 window around the pixel, a being north-west and i south-east
+	var w [9]float64
+	for dy := 0; dy < 3; dy++ {
+		for dx := 0; dx < 3; dx++ {
+			v := e.Data[(y+dy)*stride+x+dx]
+			if math.IsNaN(v) {
+				v = center
+			}
+			w[dy*3+dx] = v
+		}
+	}
+	a, b, c, d, f, g, h, i := w[0], w[1], w[2], w[3], w[5], w[6], w[7], w[8]
+
+	factor := e.zFactor() / e.scale()
+	east = ((c + 2*f + i) - (a + 2*d + g)) / (8 * e.CellSizeX) * factor
+	north = ((a + 2*b + c) - (g + 2*h + i)) / (8 * e.CellSizeY) * factor
+	return east, north, true
+}
+
+func (e Elevation) zFactor() float64 {
+	if e.ZFactor == 0 {
+		return 1
+	}
+	return e.ZFactor
+}
+
+func (e Elevation) scale() float64 {
+	if e.Scale == 0 {
+		return 1
+	}
+	return e.Scale
+}
+
+// terrain computes a value for every pixel of the rendered area from its gradient, NaN for NoData pixels.
+func (e Elevation) terrain(value func(east, north float64) float64) []float64 {
+	out := make([]float64, e.Width*e.Height)
+	for y := 0; y < e.Height; y++ {
+		for x := 0; x < e.Width; x++ {
+			east, north, ok := e.gradient(x, y)
+			if !ok {
+				out[y*e.Width+x] = math.NaN()
+				continue
+			}
+			out[y*e.Width+x] = value(east, north)
+		}
+	}
+
+	return out
+}
+
+// TerrainOption configures the SlopeDrawer and the AspectDrawer
+type TerrainOption func(*terrainOptions)
+
+type terrainOptions struct {
+	style   *models.RasterStyle
+	percent bool
+}
+
+// TerrainStyle colors the values through the color map of the style, instead of rendering them as grayscale.
+func TerrainStyle(style models.RasterStyle) TerrainOption {
+	return func(options *terrainOptions) {
+		options.style = &style
+	}
+}
+
+// Percent measures slopes as the rise over the run times 100, instead of degrees. The AspectDrawer ignores it.
+func Percent() TerrainOption {
+	return func(options *terrainOptions) {
+		options.percent = true
+	}
+}
+
+// draw colors values through the style of the options, or stretches them from 0 (black) to max (white) when there is none.
+func (to terrainOptions) draw(values []float64, width, height int, max float64) (image.Image, error) {
+	if to.style != nil && len(to.style.ColorMap) > 0 {
+		return NewRGBDrawer(values, width, height, StyleOption(*to.style)).Draw()
+	}
+
+	return Grayscale(values, width, height, 0, max).Draw()
+}
@@ -0,0 +1,209 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Source calls yield with the values to compute statistics from, block by block, and returns the first error of yield.
+// ComputeSource reads a source several times, so it must yield the same values every time.
+type Source func(yield func(block []float64) error) error
+
+const (
+	// selectionBins is the number of bins the values are split into to narrow down the search of a percentile
+	selectionBins = 1024
+	// collectLimit is the number of values below which the values a percentile is searched among are kept and sorted
+	collectLimit = 1 << 16
+)
+
+// ComputeSource computes the same statistics as Compute from the values of source, without holding them all in memory.
+// It reads source a few times: once for the count, min, max, mean and standard deviation, once for the histogram,
+// and a few more to narrow down the exact percentiles to small enough sets of values to sort.
+func ComputeSource(source Source, opts ...Option) (Statistics, error) {
+	o := newOptions(opts...)
+
+	var s Statistics
+	var mean, m2 float64
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	err := source(func(block []float64) error {
+		for _, v := range block {
+			if !o.valid(v) {
+				continue
+			}
+
+			//Welford's algorithm, which does not lose precision on large counts
+			s.Count++
+			delta := v - mean
+			mean += delta / float64(s.Count)
+			m2 += delta * (v - mean)
+			s.Min, s.Max = math.Min(s.Min, v), math.Max(s.Max, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return Statistics{}, err
+	}
+	if s.Count == 0 {
+		return Compute(nil, opts...), nil
+	}
+	s.Mean = mean
+	s.StdDev = math.Sqrt(m2 / float64(s.Count))
+
+	s.Histogram = Histogram{Min: s.Min, Max: s.Max, Counts: make([]int, o.bins)}
+	err = source(func(block []float64) error {
+		for _, v := range block {
+			if o.valid(v) {
+				s.Histogram.Counts[s.Histogram.bin(v)]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	//a percentile interpolates between the values at the two closest ranks, every rank is searched for at once
+	selections := make(map[int]*selection)
+	for _, rank := range o.percentiles {
+		position := math.Max(0, math.Min(1, rank/100)) * float64(s.Count-1)
+		for _, k := range []int{int(math.Floor(position)), int(math.Ceil(position))} {
+			selections[k] = &selection{rank: k, min: s.Min, max: s.Max, count: s.Count}
+		}
+	}
+	if err = selectRanks(source, o, selections); err != nil {
+		return Statistics{}, err
+	}
+
+	s.Percentiles = make([]Percentile, 0, len(o.percentiles))
+	for _, rank := range o.percentiles {
+		position := math.Max(0, math.Min(1, rank/100)) * float64(s.Count-1)
+		lower := selections[int(math.Floor(position))].value
+		upper := selections[int(math.Ceil(position))].value
+		s.Percentiles = append(s.Percentiles, Percentile{Rank: rank, Value: lower + (position-math.Floor(position))*(upper-lower)})
+	}
+	sort.Slice(s.Percentiles, func(i, j int) bool { return s.Percentiles[i].Rank < s.Percentiles[j].Rank })
+
+	return s, nil
+}
+
+// selection searches for the value at a rank, from 0, of the sorted values among the count values between min and max inclusive,
+// below of the values being lower than min.
+type selection struct {
+	rank     int
+	min, max float64
+	below    int
+	count    int
+
+	value float64
+	done  bool
+	// the values between min and max, when there are few enough of them to be sorted
+	collect bool
+	values  []float64
+	// the values between min and max split in selectionBins bins, otherwise
+	bins []selectionBin
+}
+
+type selectionBin struct {
+	count    int
+	min, max float64
+}
+
+// selectRanks reads source until the value of every selection is found.
+// Each pass splits the values of every selection in bins and keeps searching in the bin of its rank, whose values are all the same
+// or few enough to be sorted after a few passes.
+func selectRanks(source Source, o options, selections map[int]*selection) error {
+	for {
+		var pending []*selection
+		for _, sel := range selections {
+			if sel.done {
+				continue
+			}
+
+			pending = append(pending, sel)
+			if sel.count <= collectLimit {
+				sel.collect = true
+			}
+			if sel.collect {
+				sel.values = make([]float64, 0, sel.count)
+				continue
+			}
+
+			sel.bins = make([]selectionBin, selectionBins)
+			for i := range sel.bins {
+				sel.bins[i] = selectionBin{min: math.Inf(1), max: math.Inf(-1)}
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		err := source(func(block []float64) error {
+			for _, v := range block {
+				if !o.valid(v) {
+					continue
+				}
+
+				for _, sel := range pending {
+					if v < sel.min || v > sel.max {
+						continue
+					}
+					if sel.collect {
+						sel.values = append(sel.values, v)
+						continue
+					}
+
+					bin := &sel.bins[sel.bin(v)]
+					bin.count++
+					bin.min, bin.max = math.Min(bin.min, v), math.Max(bin.max, v)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, sel := range pending {
+			sel.next()
+		}
+	}
+}
+
+// bin returns the bin of v, which must be between min and max. Bins are monotonic, so the values between the min and max of a bin
+// are all in that bin.
+func (sel *selection) bin(v float64) int {
+	if sel.max == sel.min {
+		return 0
+	}
+
+	return min(int((v-sel.min)/(sel.max-sel.min)*selectionBins), selectionBins-1)
+}
+
+// next narrows down the search to the bin of the rank, after a pass.
+func (sel *selection) next() {
+	if sel.collect {
+		sort.Float64s(sel.values)
+		sel.value, sel.done, sel.values = sel.values[sel.rank-sel.below], true, nil
+		return
+	}
+
+	below := sel.below
+	for _, bin := range sel.bins {
+		if sel.rank >= below+bin.count {
+			below += bin.count
+			continue
+		}
+
+		switch {
+		case bin.min == bin.max:
+			sel.value, sel.done = bin.min, true
+		case bin.count == sel.count:
+			//the bins are too narrow for floats to tell them apart, the values are sorted instead
+			sel.collect = true
+		default:
+			sel.min, sel.max, sel.below, sel.count = bin.min, bin.max, below, bin.count
+		}
+		break
+	}
+	sel.bins = nil
+}
@@ -0,0 +1,209 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultBins is the number of bins of the histogram, when not specified otherwise
+	DefaultBins = 256
+)
+
+// DefaultPercentiles are the percentiles computed, when not specified otherwise
+var DefaultPercentiles = []float64{1, 2, 5, 25, 50, 75, 95, 98, 99}
+
+// Statistics describes the valid values of a band, i.e. without NoData, NaN and infinite values.
+// When there are no valid values, every field is zero.
+type Statistics struct {
+	// Count is the number of valid values
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	// Percentiles are sorted by rank
+	Percentiles []Percentile `json:"percentiles"`
+	Histogram   Histogram    `json:"histogram"`
+}
+
+// Percentile is the value below which Rank percent of the values fall
+type Percentile struct {
+	Rank  float64 `json:"rank"`
+	Value float64 `json:"value"`
+}
+
+// Histogram counts the values falling in Bins equal bins between Min and Max. Values equal to Max are in the last bin.
+type Histogram struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Counts []int   `json:"counts"`
+}
+
+type Option func(options *options)
+
+type options struct {
+	bins        int
+	percentiles []float64
+	noData      *float64
+}
+
+// Bins sets the number of bins of the histogram. Defaults to DefaultBins.
+func Bins(bins int) Option {
+	return func(options *options) {
+		options.bins = bins
+	}
+}
+
+// Percentiles sets the percentiles to compute, from 0 to 100. Defaults to DefaultPercentiles.
+func Percentiles(ranks ...float64) Option {
+	return func(options *options) {
+		options.percentiles = ranks
+	}
+}
+
+// NoData ignores the values equal to noData.
+func NoData(noData float64) Option {
+	return func(options *options) {
+		options.noData = &noData
+	}
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		bins:        DefaultBins,
+		percentiles: DefaultPercentiles,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bins < 1 {
+		o.bins = 1
+	}
+
+	return o
+}
+
+// valid reports whether v is neither NaN, infinite nor NoData.
+func (o options) valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0) && (o.noData == nil || v != *o.noData)
+}
+
+// Compute computes the statistics of data, ignoring NaN, infinite values and NoData. data is not modified.
+func Compute(data []float64, opts ...Option) Statistics {
+	o := newOptions(opts...)
+
+	values := make([]float64, 0, len(data))
+	for _, v := range data {
+		if o.valid(v) {
+			values = append(values, v)
+		}
+	}
+
+	var s Statistics
+	s.Histogram.Counts = make([]int, o.bins)
+	s.Percentiles = make([]Percentile, 0, len(o.percentiles))
+	if len(values) == 0 {
+		for _, rank := range o.percentiles {
+			s.Percentiles = append(s.Percentiles, Percentile{Rank: rank})
+		}
+		sort.Slice(s.Percentiles, func(i, j int) bool { return s.Percentiles[i].Rank < s.Percentiles[j].Rank })
+		return s
+	}
+
+	sort.Float64s(values)
+	s.Count = len(values)
+	s.Min, s.Max = values[0], values[len(values)-1]
+
+	//Welford's algorithm, which does not lose precision on large counts
+	var mean, m2 float64
+	for i, v := range values {
+		delta := v - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (v - mean)
+	}
+	s.Mean = mean
+	s.StdDev = math.Sqrt(m2 / float64(len(values)))
+
+	for _, rank := range o.percentiles {
+		s.Percentiles = append(s.Percentiles, Percentile{Rank: rank, Value: percentile(values, rank)})
+	}
+	sort.Slice(s.Percentiles, func(i, j int) bool { return s.Percentiles[i].Rank < s.Percentiles[j].Rank })
+
+	s.Histogram.Min, s.Histogram.Max = s.Min, s.Max
+	for _, v := range values {
+		s.Histogram.Counts[s.Histogram.bin(v)]++
+	}
+
+	return s
+}
+
+// Percentile returns the value below which rank percent of the values fall.
+// The percentiles computed by Compute are exact, any other rank is estimated from the histogram.
+func (s Statistics) Percentile(rank float64) float64 {
+	for _, p := range s.Percentiles {
+		if p.Rank == rank {
+			return p.Value
+		}
+	}
+
+	return s.Histogram.Percentile(rank)
+}
+
+// Percentile estimates the value below which rank percent of the values fall, assuming the values are evenly spread in each bin.
+func (h Histogram) Percentile(rank float64) float64 {
+	var total int
+	for _, count := range h.Counts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	target := math.Max(0, math.Min(1, rank/100)) * float64(total)
+	width := h.binWidth()
+	var cumulative float64
+	for i, count := range h.Counts {
+		if count > 0 && cumulative+float64(count) >= target {
+			return h.Min + (float64(i)+(target-cumulative)/float64(count))*width
+		}
+		cumulative += float64(count)
+	}
+
+	return h.Max
+}
+
+// bin returns the index of the bin of v, which must be between Min and Max.
+func (h Histogram) bin(v float64) int {
+	width := h.binWidth()
+	if width == 0 {
+		return 0
+	}
+
+	i := int((v - h.Min) / width)
+	if i >= len(h.Counts) {
+		i = len(h.Counts) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+
+	return i
+}
+
+func (h Histogram) binWidth() float64 {
+	if len(h.Counts) == 0 {
+		return 0
+	}
+
+	return (h.Max - h.Min) / float64(len(h.Counts))
+}
+
+// percentile interpolates linearly between the closest ranks of sorted, which must not be empty.
+func percentile(sorted []float64, rank float64) float64 {
+	position := math.Max(0, math.Min(1, rank/100)) * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	return sorted[lower] + (position-float64(lower))*(sorted[upper]-sorted[lower])
+}
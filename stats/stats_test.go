@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"errors"
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	data := []float64{4, 2, -9999, 8, math.NaN(), 6, math.Inf(1), 10}
+
+	s := Compute(data, NoData(-9999), Bins(4), Percentiles(50, 0, 100, 25))
+	assert.Equal(t, s.Count, 5)
+	assert.Equal(t, s.Min, 2.0)
+	assert.Equal(t, s.Max, 10.0)
+	assert.Equal(t, s.Mean, 6.0)
+	assert.Equal(t, s.StdDev, math.Sqrt(8))
+	assert.DeepEqual(t, s.Percentiles, []Percentile{{0, 2}, {25, 4}, {50, 6}, {100, 10}})
+	assert.DeepEqual(t, s.Histogram, Histogram{Min: 2, Max: 10, Counts: []int{1, 1, 1, 2}})
+
+	t.Run("POSITIVE DATA", func(t *testing.T) {
+		//the min of data without zeros is not 0
+		s := Compute([]float64{100, 200, 300})
+		assert.Equal(t, s.Min, 100.0)
+		assert.Equal(t, len(s.Histogram.Counts), DefaultBins)
+		assert.Equal(t, len(s.Percentiles), len(DefaultPercentiles))
+	})
+
+	t.Run("NO VALID VALUES", func(t *testing.T) {
+		s := Compute([]float64{math.NaN(), -9999}, NoData(-9999), Bins(2), Percentiles(50))
+		assert.DeepEqual(t, s, Statistics{
+			Percentiles: []Percentile{{50, 0}},
+			Histogram:   Histogram{Counts: []int{0, 0}},
+		})
+	})
+
+	t.Run("CONSTANT", func(t *testing.T) {
+		s := Compute([]float64{3, 3, 3}, Bins(3))
+		assert.Equal(t, s.StdDev, 0.0)
+		assert.DeepEqual(t, s.Histogram.Counts, []int{3, 0, 0})
+	})
+
+	t.Run("NOT MODIFIED", func(t *testing.T) {
+		data := []float64{3, 1, 2}
+		Compute(data)
+		assert.DeepEqual(t, data, []float64{3, 1, 2})
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	data := make([]float64, 101)
+	for i := range data {
+		data[i] = float64(i)
+	}
+
+	s := Compute(data, Bins(10), Percentiles(2, 98))
+	assert.Equal(t, s.Percentile(2), 2.0)
+	assert.Equal(t, s.Percentile(98), 98.0)
+
+	//not computed, so estimated from the histogram
+	assert.Assert(t, math.Abs(s.Percentile(50)-50) < 1, "%f", s.Percentile(50))
+	assert.Equal(t, s.Percentile(0), 0.0)
+	assert.Equal(t, s.Percentile(100), 100.0)
+	assert.Equal(t, Histogram{Counts: []int{0, 0}}.Percentile(50), 0.0)
+}
+
+func TestComputeSource(t *testing.T) {
+	//blocks of values with many duplicates, so that the search of the percentiles narrows down bins of equal values
+	var blocks [][]float64
+	var data []float64
+	for b := 0; b < 10; b++ {
+		block := make([]float64, 20000)
+		for i := range block {
+			block[i] = float64((b*7919+i*104729)%5000) / 7
+			if i%1000 == 0 {
+				block[i] = -9999
+			}
+		}
+		blocks = append(blocks, block)
+		data = append(data, block...)
+	}
+
+	var reads int
+	source := func(yield func([]float64) error) error {
+		reads++
+		for _, block := range blocks {
+			if err := yield(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	options := []Option{NoData(-9999), Bins(16), Percentiles(0, 1, 33.3, 50, 99.9, 100)}
+	s, err := ComputeSource(source, options...)
+	assert.NilError(t, err)
+	expected := Compute(data, options...)
+	assert.Equal(t, s.Count, expected.Count)
+	assert.Equal(t, s.Min, expected.Min)
+	assert.Equal(t, s.Max, expected.Max)
+	assert.Assert(t, math.Abs(s.Mean-expected.Mean) < 1e-9)
+	assert.Assert(t, math.Abs(s.StdDev-expected.StdDev) < 1e-9)
+	assert.DeepEqual(t, s.Percentiles, expected.Percentiles)
+	assert.DeepEqual(t, s.Histogram, expected.Histogram)
+	assert.Assert(t, reads <= 6, "%d reads", reads)
+
+	t.Run("NO VALID VALUES", func(t *testing.T) {
+		s, err := ComputeSource(func(yield func([]float64) error) error {
+			return yield([]float64{math.NaN()})
+		}, Bins(2), Percentiles(50))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, s, Compute(nil, Bins(2), Percentiles(50)))
+	})
+
+	t.Run("ERROR", func(t *testing.T) {
+		_, err := ComputeSource(func(yield func([]float64) error) error {
+			return errors.New("read failed")
+		})
+		assert.Error(t, err, "read failed")
+	})
+}
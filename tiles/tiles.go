@@ -0,0 +1,177 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// SRS is the spatial reference system of the tile grid, Web Mercator
+	SRS = "EPSG:3857"
+	// EarthRadius is the radius of the sphere used by Web Mercator, in meters
+	EarthRadius = 6378137.0
+	// OriginShift is half the width of the Web Mercator extent, in meters
+	OriginShift = math.Pi * EarthRadius
+	// MaxLatitude is the latitude at which Web Mercator is cut off to make the world a square
+	MaxLatitude = 85.05112877980659
+	// DefaultTileSize is the size of a tile in pixels, when not specified otherwise
+	DefaultTileSize = 256
+)
+
+// Extent is the whole Web Mercator extent (minX, minY, maxX, maxY), in meters
+var Extent = [4]float64{-OriginShift, -OriginShift, OriginShift, OriginShift}
+
+// Tile addresses a tile of the Web Mercator grid with the XYZ scheme, where y grows from north to south.
+// Use FlipY to convert from and to the TMS scheme, where y grows from south to north.
+type Tile struct {
+	Z uint
+	X uint
+	Y uint
+}
+
+// Valid reports whether the tile exists at its zoom level.
+func (t Tile) Valid() bool {
+	return t.Z < 32 && t.X < 1<<t.Z && t.Y < 1<<t.Z
+}
+
+// FlipY converts a tile between the XYZ and TMS schemes.
+func (t Tile) FlipY() Tile {
+	return Tile{Z: t.Z, X: t.X, Y: (1 << t.Z) - 1 - t.Y}
+}
+
+// Bounds returns the extent of the tile (minX, minY, maxX, maxY) in Web Mercator meters.
+func (t Tile) Bounds() [4]float64 {
+	size := 2 * OriginShift / float64(uint64(1)<<t.Z)
+	minX := -OriginShift + float64(t.X)*size
+	maxY := OriginShift - float64(t.Y)*size
+
+	return [4]float64{minX, maxY - size, minX + size, maxY}
+}
+
+// LonLatBounds returns the extent of the tile (minLon, minLat, maxLon, maxLat) in degrees.
+func (t Tile) LonLatBounds() [4]float64 {
+	bounds := t.Bounds()
+	minLon, minLat := MetersToLonLat(bounds[0], bounds[1])
+	maxLon, maxLat := MetersToLonLat(bounds[2], bounds[3])
+
+	return [4]float64{minLon, minLat, maxLon, maxLat}
+}
+
+// Quadkey returns the Bing Maps quadkey of the tile. The quadkey of the zoom 0 tile is empty.
+func (t Tile) Quadkey() string {
+	var sb strings.Builder
+	for z := t.Z; z > 0; z-- {
+		digit := byte('0')
+		mask := uint(1) << (z - 1)
+		if t.X&mask != 0 {
+			digit++
+		}
+		if t.Y&mask != 0 {
+			digit += 2
+		}
+		sb.WriteByte(digit)
+	}
+
+	return sb.String()
+}
+
+func (t Tile) String() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// FromQuadkey returns the tile addressed by a Bing Maps quadkey.
+func FromQuadkey(quadkey string) (Tile, error) {
+	if len(quadkey) >= 32 {
+		return Tile{}, errors.New("quadkey is too long")
+	}
+
+	tile := Tile{Z: uint(len(quadkey))}
+	for i, digit := range quadkey {
+		mask := uint(1) << (tile.Z - 1 - uint(i))
+		switch digit {
+		case '0':
+		case '1':
+			tile.X |= mask
+		case '2':
+			tile.Y |= mask
+		case '3':
+			tile.X |= mask
+			tile.Y |= mask
+		default:
+			return Tile{}, fmt.Errorf("invalid quadkey digit %q", digit)
+		}
+	}
+
+	return tile, nil
+}
+
+// FromLonLat returns the tile containing the point at the given zoom level.
+// Latitudes beyond MaxLatitude fall in the northern or southern-most tiles.
+func FromLonLat(lon, lat float64, z uint) Tile {
+	x, y := LonLatToMeters(lon, lat)
+	return FromMeters(x, y, z)
+}
+
+// FromMeters returns the tile containing the Web Mercator point at the given zoom level.
+// Points outside the Web Mercator extent fall in the closest tile.
+func FromMeters(x, y float64, z uint) Tile {
+	n := float64(uint64(1) << z)
+	clamp := func(v float64) uint {
+		return uint(math.Max(0, math.Min(n-1, math.Floor(v))))
+	}
+
+	return Tile{
+		Z: z,
+		X: clamp((x + OriginShift) / (2 * OriginShift) * n),
+		Y: clamp((OriginShift - y) / (2 * OriginShift) * n),
+	}
+}
+
+// Range returns the top-left and bottom-right tiles (XYZ) covering the Web Mercator bounds (minX, minY, maxX, maxY) at the given zoom level.
+// Bounds that lie on the edge of a tile do not cover the tile beyond that edge.
+func Range(bounds [4]float64, z uint) (min, max Tile) {
+	const epsilon = 1e-9
+	n := float64(uint64(1) << z)
+	clamp := func(v float64) uint {
+		return uint(math.Max(0, math.Min(n-1, v)))
+	}
+
+	minX := (bounds[0] + OriginShift) / (2 * OriginShift) * n
+	maxX := (bounds[2] + OriginShift) / (2 * OriginShift) * n
+	minY := (OriginShift - bounds[3]) / (2 * OriginShift) * n
+	maxY := (OriginShift - bounds[1]) / (2 * OriginShift) * n
+
+	min = Tile{Z: z, X: clamp(math.Floor(minX + epsilon)), Y: clamp(math.Floor(minY + epsilon))}
+	max = Tile{Z: z, X: clamp(math.Ceil(maxX-epsilon) - 1), Y: clamp(math.Ceil(maxY-epsilon) - 1)}
+	if max.X < min.X {
+		max.X = min.X
+	}
+	if max.Y < min.Y {
+		max.Y = min.Y
+	}
+
+	return min, max
+}
+
+// Resolution returns the size of a pixel in meters at the given zoom level, for tiles of tileSize pixels.
+func Resolution(z uint, tileSize uint) float64 {
+	return 2 * OriginShift / (float64(tileSize) * float64(uint64(1)<<z))
+}
+
+// LonLatToMeters converts a point in degrees to Web Mercator meters. Latitudes are clamped to MaxLatitude.
+func LonLatToMeters(lon, lat float64) (x, y float64) {
+	lat = math.Max(-MaxLatitude, math.Min(MaxLatitude, lat))
+
+	x = lon * OriginShift / 180
+	y = math.Log(math.Tan((90+lat)*math.Pi/360)) * EarthRadius
+	return x, y
+}
+
+// MetersToLonLat converts a Web Mercator point in meters to degrees.
+func MetersToLonLat(x, y float64) (lon, lat float64) {
+	lon = x / OriginShift * 180
+	lat = 180 / math.Pi * (2*math.Atan(math.Exp(y/EarthRadius)) - math.Pi/2)
+	return lon, lat
+}
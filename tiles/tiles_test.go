@@ -0,0 +1,89 @@
+package tiles
+
+import (
+	"gotest.tools/v3/assert"
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-6
+
+func assertBounds(t *testing.T, actual, expected [4]float64) {
+	t.Helper()
+	for i := range actual {
+		assert.Check(t, math.Abs(actual[i]-expected[i]) < epsilon, "bound %d: %f != %f", i, actual[i], expected[i])
+	}
+}
+
+func TestBounds(t *testing.T) {
+	assertBounds(t, Tile{}.Bounds(), Extent)
+	assertBounds(t, Tile{Z: 1, X: 0, Y: 0}.Bounds(), [4]float64{-OriginShift, 0, 0, OriginShift})
+	assertBounds(t, Tile{Z: 1, X: 1, Y: 1}.Bounds(), [4]float64{0, -OriginShift, OriginShift, 0})
+	assertBounds(t, Tile{Z: 0}.LonLatBounds(), [4]float64{-180, -MaxLatitude, 180, MaxLatitude})
+}
+
+func TestFromLonLat(t *testing.T) {
+	//Rome
+	tile := FromLonLat(12.4964, 41.9028, 10)
+	assert.Equal(t, tile, Tile{Z: 10, X: 547, Y: 380})
+
+	lonLat := tile.LonLatBounds()
+	assert.Check(t, lonLat[0] <= 12.4964 && 12.4964 <= lonLat[2])
+	assert.Check(t, lonLat[1] <= 41.9028 && 41.9028 <= lonLat[3])
+
+	//beyond the Web Mercator extent
+	assert.Equal(t, FromLonLat(180, 90, 2), Tile{Z: 2, X: 3, Y: 0})
+	assert.Equal(t, FromLonLat(-180, -90, 2), Tile{Z: 2, X: 0, Y: 3})
+}
+
+func TestLonLatToMeters(t *testing.T) {
+	x, y := LonLatToMeters(12.4964, 41.9028)
+	lon, lat := MetersToLonLat(x, y)
+	assert.Check(t, math.Abs(lon-12.4964) < epsilon)
+	assert.Check(t, math.Abs(lat-41.9028) < epsilon)
+
+	x, y = LonLatToMeters(180, MaxLatitude)
+	assert.Check(t, math.Abs(x-OriginShift) < epsilon)
+	assert.Check(t, math.Abs(y-OriginShift) < 1e-3)
+}
+
+func TestFlipY(t *testing.T) {
+	assert.Equal(t, Tile{Z: 3, X: 2, Y: 1}.FlipY(), Tile{Z: 3, X: 2, Y: 6})
+	assert.Equal(t, Tile{Z: 3, X: 2, Y: 1}.FlipY().FlipY(), Tile{Z: 3, X: 2, Y: 1})
+}
+
+func TestQuadkey(t *testing.T) {
+	tile := Tile{Z: 3, X: 3, Y: 5}
+	assert.Equal(t, tile.Quadkey(), "213")
+	assert.Equal(t, Tile{}.Quadkey(), "")
+
+	parsed, err := FromQuadkey("213")
+	assert.NilError(t, err)
+	assert.Equal(t, parsed, tile)
+
+	_, err = FromQuadkey("214")
+	assert.ErrorContains(t, err, "invalid quadkey digit")
+}
+
+func TestValid(t *testing.T) {
+	assert.Check(t, Tile{Z: 2, X: 3, Y: 3}.Valid())
+	assert.Check(t, !Tile{Z: 2, X: 4, Y: 0}.Valid())
+	assert.Check(t, !Tile{Z: 0, X: 0, Y: 1}.Valid())
+}
+
+func TestRange(t *testing.T) {
+	min, max := Range(Extent, 2)
+	assert.Equal(t, min, Tile{Z: 2, X: 0, Y: 0})
+	assert.Equal(t, max, Tile{Z: 2, X: 3, Y: 3})
+
+	//the bounds of a single tile only cover that tile
+	tile := Tile{Z: 5, X: 17, Y: 11}
+	min, max = Range(tile.Bounds(), 5)
+	assert.Equal(t, min, tile)
+	assert.Equal(t, max, tile)
+}
+
+func TestResolution(t *testing.T) {
+	assert.Check(t, math.Abs(Resolution(0, DefaultTileSize)-156543.03392804097) < epsilon)
+	assert.Check(t, math.Abs(Resolution(1, 512)-Resolution(2, DefaultTileSize)) < epsilon)
+}
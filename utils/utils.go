@@ -2,69 +2,93 @@ package utils
 
 import (
 	"github.com/airbusgeo/godal"
+	"github.com/canghel3/raster2image/stats"
+	"github.com/canghel3/raster2image/tiles"
+	"math"
 	"math/rand"
 )
 
+// MinMaxDs returns the min and max of all the bands of the dataset together, ignoring NoData.
+//
+// Deprecated: use the Statistics of a raster.Driver, which does not read whole bands, or the stats package.
 func MinMaxDs(ds *godal.Dataset) (min, max float64, err error) {
-	switch len(ds.Bands()) {
-	case 1:
-		band := ds.Bands()[0]
-		bandStructure := band.Structure()
+	ranges, err := MinMaxBands(ds, nil)
+	if err != nil || len(ranges) == 0 {
+		return min, max, err
+	}
+
+	min, max = ranges[0][0], ranges[0][1]
+	for _, r := range ranges[1:] {
+		min, max = math.Min(min, r[0]), math.Max(max, r[1])
+	}
+	return min, max, nil
+}
 
-		var data = make([]float64, bandStructure.SizeX*bandStructure.SizeY)
-		err := band.Read(0, 0, data, bandStructure.SizeX, bandStructure.SizeY)
+// MinMaxBands returns the min and max of every band in the dataset, in band order, ignoring NoData.
+// noData overrides the NoData value of the bands when it is not nil.
+//
+// Deprecated: use the Statistics of a raster.Driver, which does not read whole bands, or the stats package.
+func MinMaxBands(ds *godal.Dataset, noData *float64) ([][2]float64, error) {
+	ranges := make([][2]float64, len(ds.Bands()))
+	for i, band := range ds.Bands() {
+		min, max, err := MinMaxBand(band, noData)
 		if err != nil {
-			return min, max, err
+			return nil, err
 		}
 
-		min, max = MinMax(data)
+		ranges[i] = [2]float64{min, max}
 	}
 
-	return min, max, nil
+	return ranges, nil
 }
 
-func MinMax(data []float64) (min, max float64) {
-	min, max = 0, 0
-	for _, v := range data {
-		if v < min {
-			min = v
-		}
-		if v > max {
-			max = v
-		}
+// MinMaxBand reads the whole band and returns its min and max, ignoring NoData.
+// noData overrides the NoData value of the band when it is not nil.
+//
+// Deprecated: use the Statistics of a raster.Driver, which does not read whole bands, or the stats package.
+func MinMaxBand(band godal.Band, noData *float64) (min, max float64, err error) {
+	structure := band.Structure()
+
+	var data = make([]float64, structure.SizeX*structure.SizeY)
+	err = band.Read(0, 0, data, structure.SizeX, structure.SizeY)
+	if err != nil {
+		return min, max, err
 	}
-	return
-}
 
-const (
-	minExtentX = 736241.4564
-	minExtentY = 5826336.044
-	maxExtentX = 2372605.358
-	maxExtentY = 7049328.4966
-	tileSize   = 256 // Tile size in pixels
-	zoomLevel  = 7
-)
+	options := []stats.Option{stats.Bins(1), stats.Percentiles()}
+	if noData != nil {
+		options = append(options, stats.NoData(*noData))
+	} else if nd, ok := band.NoData(); ok {
+		options = append(options, stats.NoData(nd))
+	}
 
-func GenerateRandomBBoxWithinExtent() [4]float64 {
-	// Calculate the tile size in map units (meters) at zoom level 7
-	totalMapWidth := maxExtentX - minExtentX
-	totalMapHeight := maxExtentY - minExtentY
-	tileWidth := totalMapWidth / float64(1<<zoomLevel)   // Width of one tile in meters
-	tileHeight := totalMapHeight / float64(1<<zoomLevel) // Height of one tile in meters
+	statistics, err := stats.ComputeSource(func(yield func(block []float64) error) error {
+		return yield(data)
+	}, options...)
+	return statistics.Min, statistics.Max, err
+}
 
-	// Determine the maximum number of tiles in the x and y ranges within the extent
-	maxTileX := int(totalMapWidth / tileWidth)
-	maxTileY := int(totalMapHeight / tileHeight)
+// MinMax returns the min and max of data, ignoring NaN and infinite values.
+// If data has no finite values, both are 0.
+//
+// Deprecated: use stats.Compute or stats.ComputeSource.
+func MinMax(data []float64) (min, max float64) {
+	statistics, _ := stats.ComputeSource(func(yield func(block []float64) error) error {
+		return yield(data)
+	}, stats.Bins(1), stats.Percentiles())
+	return statistics.Min, statistics.Max
+}
 
-	// Randomly select a tile index within the calculated range
-	tileX := rand.Intn(maxTileX)
-	tileY := rand.Intn(maxTileY)
+// GenerateRandomBBoxWithinExtent returns the Web Mercator bbox of a random tile at the given zoom level
+// among the tiles covering the extent (minX, minY, maxX, maxY), in EPSG:3857.
+func GenerateRandomBBoxWithinExtent(extent [4]float64, zoom uint) [4]float64 {
+	min, max := tiles.Range(extent, zoom)
 
-	// Calculate the bounding box coordinates for the selected tile
-	minX := minExtentX + float64(tileX)*tileWidth
-	maxX := minX + tileWidth
-	minY := maxExtentY - float64(tileY+1)*tileHeight
-	maxY := minY + tileHeight
+	tile := tiles.Tile{
+		Z: zoom,
+		X: min.X + uint(rand.Intn(int(max.X-min.X+1))),
+		Y: min.Y + uint(rand.Intn(int(max.Y-min.Y+1))),
+	}
 
-	return [4]float64{minX, minY, maxX, maxY}
+	return tile.Bounds()
 }